@@ -0,0 +1,165 @@
+package claquete
+
+import (
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// earthRadius is the mean radius of Earth in kilometers.
+const earthRadius = 6371.0
+
+var (
+	reCEP         = regexp.MustCompile(`(?i)(?:cep:?\s*)?\b(\d{5})-?(\d{3})\b`)
+	reState       = regexp.MustCompile(`(?:\s+-\s+|/|,\s*)([A-Z]{2})\s*$`)
+	reCoordinates = regexp.MustCompile(`^(-?\d+(?:\.\d+)?),\s*(-?\d+(?:\.\d+)?)`)
+)
+
+type (
+	// Address is the address of a cinema, as in "Av. Donato Quintino, 90 -
+	// Cidade Nova, Montes Claros - MG". Parts missing from the address
+	// line are left empty.
+	Address struct {
+		Street       string `json:"street"`
+		Number       string `json:"number,omitempty"`
+		Complement   string `json:"complement,omitempty"`
+		Neighborhood string `json:"neighborhood,omitempty"`
+		City         string `json:"city,omitempty"`
+		// State is the federative unit, such as MG.
+		State string `json:"state,omitempty"`
+		// CEP is the postal code, as in 39400-000.
+		CEP string `json:"cep,omitempty"`
+	}
+
+	// Coordinates is a point in the map, in degrees.
+	Coordinates struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+)
+
+// parseAddress parses an address line as in "Av. Donato Quintino, 90 -
+// Cidade Nova, Montes Claros - MG", with an optional CEP anywhere.
+func parseAddress(line string) Address {
+	var result Address
+
+	if res := reCEP.FindStringSubmatch(line); len(res) == 3 {
+		result.CEP = res[1] + "-" + res[2]
+		line = strings.Replace(line, res[0], "", 1)
+	}
+	line = strings.Trim(strings.TrimSpace(line), ",-")
+	line = strings.TrimSpace(line)
+
+	if res := reState.FindStringSubmatchIndex(line); res != nil {
+		if fu := line[res[2]:res[3]]; isFederativeUnitValid(fu) {
+			result.State = fu
+			line = strings.TrimSpace(line[:res[0]])
+		}
+	}
+
+	var parts []string
+	for _, part := range strings.Split(line, " - ") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return result
+	}
+
+	// The last part is the neighborhood and the city, unless there's a
+	// single part holding the street only.
+	if len(parts) > 1 {
+		last := parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+		if i := strings.LastIndex(last, ","); i >= 0 {
+			result.Neighborhood = strings.TrimSpace(last[:i])
+			result.City = strings.TrimSpace(last[i+1:])
+		} else {
+			result.City = last
+		}
+	}
+
+	// The first part is the street and number, the others complement it
+	street := parts[0]
+	if i := strings.LastIndex(street, ","); i >= 0 {
+		number := strings.TrimSpace(street[i+1:])
+		if isAddressNumber(number) {
+			result.Number = number
+			street = street[:i]
+		}
+	}
+	result.Street = strings.TrimSpace(street)
+	result.Complement = strings.Join(parts[1:], " - ")
+
+	return result
+}
+
+// isAddressNumber reports whether s is an address number, as in "90",
+// "1.500", "s/n" or "1200 A".
+func isAddressNumber(s string) bool {
+	lower := strings.ToLower(s)
+	if lower == "s/n" || lower == "s/nº" || lower == "sn" {
+		return true
+	}
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// parseMapLink returns the coordinates in a map link as in
+// "https://maps.google.com/maps?q=-16.7211,-43.8647".
+func parseMapLink(link string) (*Coordinates, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, false
+	}
+
+	query := u.Query()
+	for _, key := range []string{"q", "ll", "query", "daddr"} {
+		if c, ok := parseCoordinates(query.Get(key)); ok {
+			return c, true
+		}
+	}
+	// Links as in https://www.google.com/maps/@-16.7211,-43.8647,17z
+	if i := strings.Index(u.Path, "@"); i >= 0 {
+		return parseCoordinates(u.Path[i+1:])
+	}
+	return nil, false
+}
+
+func parseCoordinates(s string) (*Coordinates, bool) {
+	res := reCoordinates.FindStringSubmatch(strings.TrimSpace(s))
+	if len(res) != 3 {
+		return nil, false
+	}
+	lat, _ := strconv.ParseFloat(res[1], 64)
+	lng, _ := strconv.ParseFloat(res[2], 64)
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, false
+	}
+	return &Coordinates{Latitude: lat, Longitude: lng}, true
+}
+
+// Distance returns the great-circle distance to other in kilometers.
+func (c Coordinates) Distance(other Coordinates) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(other.Latitude - c.Latitude)
+	dLng := rad(other.Longitude - c.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(c.Latitude))*math.Cos(rad(other.Latitude))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// SortByDistance sorts cinemas by their distance to from, closest first.
+// Cinemas without coordinates are left last, in their order.
+func SortByDistance(cinemas []Cinema, from Coordinates) {
+	sort.SliceStable(cinemas, func(i, j int) bool {
+		a, b := cinemas[i].Coordinates, cinemas[j].Coordinates
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return from.Distance(*a) < from.Distance(*b)
+	})
+}
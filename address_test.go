@@ -0,0 +1,90 @@
+package claquete
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected Address
+	}{
+		{
+			"Av. Donato Quintino, 90 - Cidade Nova, Montes Claros - MG",
+			Address{Street: "Av. Donato Quintino", Number: "90", Neighborhood: "Cidade Nova", City: "Montes Claros", State: MG},
+		},
+		{
+			"Rua das Flores, 1.500 - Loja 12 - Centro, Curitiba/PR, CEP 80020-000",
+			Address{Street: "Rua das Flores", Number: "1.500", Complement: "Loja 12", Neighborhood: "Centro", City: "Curitiba", State: PR, CEP: "80020-000"},
+		},
+		{
+			"Rodovia BR-101, s/n - Eirunepé - AM 69880000",
+			Address{Street: "Rodovia BR-101", Number: "s/n", City: "Eirunepé", State: AM, CEP: "69880-000"},
+		},
+		{
+			"Shopping Center",
+			Address{Street: "Shopping Center"},
+		},
+	}
+	for _, c := range cases {
+		if actual := parseAddress(c.line); actual != c.expected {
+			t.Fatalf("%s: expected %+v, got %+v", c.line, c.expected, actual)
+		}
+	}
+}
+
+func TestParseMapLink(t *testing.T) {
+	cases := map[string]*Coordinates{
+		"https://maps.google.com/maps?q=-16.7211,-43.8647":                 {-16.7211, -43.8647},
+		"https://www.google.com/maps/@-23.5505,-46.6333,17z":               {-23.5505, -46.6333},
+		"https://maps.google.com/maps?q=Av.+Donato+Quintino,+90":           nil,
+		"https://www.google.com/maps/search/?api=1&query=-3.1190,-60.0217": {-3.119, -60.0217},
+	}
+	for link, expected := range cases {
+		actual, ok := parseMapLink(link)
+		if ok != (expected != nil) || (ok && *actual != *expected) {
+			t.Fatalf("%s: expected %v, got %v", link, expected, actual)
+		}
+	}
+}
+
+func TestSortByDistance(t *testing.T) {
+	saoPaulo := Coordinates{-23.5505, -46.6333}
+	rio := Coordinates{-22.9068, -43.1729}
+	if d := saoPaulo.Distance(rio); math.Abs(d-361) > 5 {
+		t.Fatalf("expected about 361 km, got %f", d)
+	}
+
+	cinemas := []Cinema{
+		{ID: 1},
+		{ID: 2, Coordinates: &Coordinates{-16.7211, -43.8647}},
+		{ID: 3, Coordinates: &rio},
+	}
+	SortByDistance(cinemas, saoPaulo)
+	if cinemas[0].ID != 3 || cinemas[1].ID != 2 || cinemas[2].ID != 1 {
+		t.Fatalf("unexpected order %+v", cinemas)
+	}
+}
+
+func TestParseScheduleAddress(t *testing.T) {
+	f, err := os.Open("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sched, err := ParseSchedule(f, "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if sched.Cinema.Address.City != "Montes Claros" || sched.Cinema.Address.Number != "90" {
+		t.Fatalf("unexpected address %+v", sched.Cinema.Address)
+	}
+	expected := Coordinates{-16.7211, -43.8647}
+	if sched.Cinema.Coordinates == nil || *sched.Cinema.Coordinates != expected {
+		t.Fatalf("expected %v, got %v", expected, sched.Cinema.Coordinates)
+	}
+}
@@ -0,0 +1,286 @@
+package claquete
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// DefaultWorkers is the number of concurrent fetches made by the batch
+// operations when Workers isn't set.
+const DefaultWorkers = 4
+
+type (
+	// MovieResult is the outcome of fetching a single movie in GetMovies.
+	MovieResult struct {
+		ID    int    `json:"id"`
+		Movie *Movie `json:"movie,omitempty"`
+		Err   error  `json:"-"`
+	}
+
+	// ScheduleResult is the outcome of fetching a single schedule in
+	// GetSchedules.
+	ScheduleResult struct {
+		CinemaID int       `json:"cinema_id"`
+		Schedule *Schedule `json:"schedule,omitempty"`
+		Err      error     `json:"-"`
+	}
+
+	// CinemaResult is the outcome of fetching a single cinema in
+	// GetCinemasByID.
+	CinemaResult struct {
+		ID     int     `json:"id"`
+		Cinema *Cinema `json:"cinema,omitempty"`
+		Err    error   `json:"-"`
+	}
+
+	// group deduplicates concurrent calls with the same key, so only the
+	// first one does the work and the others wait for its result.
+	group struct {
+		mu    sync.Mutex
+		calls map[string]*call
+	}
+
+	call struct {
+		done chan struct{}
+		val  interface{}
+		err  error
+	}
+)
+
+// Workers sets how many items the batch operations fetch at once.
+func Workers(n int) func(*Claquete) {
+	return func(c *Claquete) {
+		c.workers = n
+	}
+}
+
+// OnProgress sets a function called by the batch operations each time an
+// item is done, with the number of items done so far and the total. Calls
+// are never concurrent.
+func OnProgress(f func(done, total int)) func(*Claquete) {
+	return func(c *Claquete) {
+		c.progress = f
+	}
+}
+
+// GetMovies retrieves the movies with the given IDs concurrently.
+func GetMovies(ids []int, options ...Options) ([]MovieResult, error) {
+	return GetMoviesContext(context.Background(), ids, options...)
+}
+
+// GetMoviesContext is like GetMovies but aborts when ctx is done.
+func GetMoviesContext(ctx context.Context, ids []int, options ...Options) ([]MovieResult, error) {
+	return NewClaquete(options...).GetMoviesContext(ctx, ids)
+}
+
+// GetMovies retrieves the movies with the given IDs concurrently. Results
+// are in the same order as ids, each with the movie or the error it got.
+// Repeated IDs are fetched once and share the same *Movie.
+func (c *Claquete) GetMovies(ids []int) ([]MovieResult, error) {
+	return c.GetMoviesContext(context.Background(), ids)
+}
+
+// GetMoviesContext is like GetMovies but aborts when ctx is done. Movies
+// not fetched by then have ctx.Err() as their error, which is also
+// returned.
+func (c *Claquete) GetMoviesContext(ctx context.Context, ids []int) ([]MovieResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result := make([]MovieResult, len(ids))
+	err := c.batch(ctx, ids, func(i, id int) {
+		v, err := c.inflight.do(ctx, "movie:"+strconv.Itoa(id), func() (interface{}, error) {
+			return c.GetMovieContext(ctx, id)
+		})
+		result[i] = MovieResult{ID: id, Err: err}
+		if err == nil {
+			result[i].Movie = v.(*Movie)
+		}
+	}, func(i, from int) {
+		result[i] = result[from]
+	})
+	return result, err
+}
+
+// GetSchedules retrieves the schedules of the given cinemas concurrently.
+func GetSchedules(cinemaIDs []int, options ...Options) ([]ScheduleResult, error) {
+	return GetSchedulesContext(context.Background(), cinemaIDs, options...)
+}
+
+// GetSchedulesContext is like GetSchedules but aborts when ctx is done.
+func GetSchedulesContext(ctx context.Context, cinemaIDs []int, options ...Options) ([]ScheduleResult, error) {
+	return NewClaquete(options...).GetSchedulesContext(ctx, cinemaIDs)
+}
+
+// GetSchedules retrieves the schedules of the given cinemas concurrently.
+// Results are in the same order as cinemaIDs, each with the schedule or
+// the error it got. Repeated IDs are fetched once and share the same
+// *Schedule.
+func (c *Claquete) GetSchedules(cinemaIDs []int) ([]ScheduleResult, error) {
+	return c.GetSchedulesContext(context.Background(), cinemaIDs)
+}
+
+// GetSchedulesContext is like GetSchedules but aborts when ctx is done.
+// Schedules not fetched by then have ctx.Err() as their error, which is
+// also returned.
+func (c *Claquete) GetSchedulesContext(ctx context.Context, cinemaIDs []int) ([]ScheduleResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result := make([]ScheduleResult, len(cinemaIDs))
+	err := c.batch(ctx, cinemaIDs, func(i, id int) {
+		v, err := c.inflight.do(ctx, "schedule:"+strconv.Itoa(id), func() (interface{}, error) {
+			return c.GetScheduleContext(ctx, id)
+		})
+		result[i] = ScheduleResult{CinemaID: id, Err: err}
+		if err == nil {
+			result[i].Schedule = v.(*Schedule)
+		}
+	}, func(i, from int) {
+		result[i] = result[from]
+	})
+	return result, err
+}
+
+// GetCinemasByID retrieves the cinemas with the given IDs concurrently.
+func GetCinemasByID(ids []int, options ...Options) ([]CinemaResult, error) {
+	return GetCinemasByIDContext(context.Background(), ids, options...)
+}
+
+// GetCinemasByIDContext is like GetCinemasByID but aborts when ctx is
+// done.
+func GetCinemasByIDContext(ctx context.Context, ids []int, options ...Options) ([]CinemaResult, error) {
+	return NewClaquete(options...).GetCinemasByIDContext(ctx, ids)
+}
+
+// GetCinemasByID retrieves the cinemas with the given IDs concurrently.
+// Results are in the same order as ids, each with the cinema or the error
+// it got. Repeated IDs are fetched once and share the same *Cinema.
+func (c *Claquete) GetCinemasByID(ids []int) ([]CinemaResult, error) {
+	return c.GetCinemasByIDContext(context.Background(), ids)
+}
+
+// GetCinemasByIDContext is like GetCinemasByID but aborts when ctx is
+// done. Cinemas not fetched by then have ctx.Err() as their error, which
+// is also returned.
+func (c *Claquete) GetCinemasByIDContext(ctx context.Context, ids []int) ([]CinemaResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result := make([]CinemaResult, len(ids))
+	err := c.batch(ctx, ids, func(i, id int) {
+		v, err := c.inflight.do(ctx, "cinema:"+strconv.Itoa(id), func() (interface{}, error) {
+			return c.GetCinemaContext(ctx, id)
+		})
+		result[i] = CinemaResult{ID: id, Err: err}
+		if err == nil {
+			result[i].Cinema = v.(*Cinema)
+		}
+	}, func(i, from int) {
+		result[i] = result[from]
+	})
+	return result, err
+}
+
+// batch calls fetch for the first occurrence of each ID using the
+// configured number of workers, then repeat for each repeated one with the
+// index of its first occurrence. IDs not fetched once ctx is done are
+// still passed to fetch, which is expected to fail with ctx.Err().
+func (c *Claquete) batch(ctx context.Context, ids []int, fetch func(i, id int), repeat func(i, from int)) error {
+	first := make(map[int]int, len(ids))
+	var unique []int
+	for i, id := range ids {
+		if _, ok := first[id]; !ok {
+			first[id] = i
+			unique = append(unique, i)
+		}
+	}
+
+	workers := c.workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	var mu sync.Mutex
+	var done int
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fetch(i, ids[i])
+				if c.progress != nil {
+					mu.Lock()
+					done++
+					c.progress(done, len(unique))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, i := range unique {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, id := range ids {
+		if from := first[id]; from != i {
+			repeat(i, from)
+		}
+	}
+
+	return ctx.Err()
+}
+
+// testHookGroupWait is called by waiters of group.do right before they
+// block on the call in flight.
+var testHookGroupWait = func() {}
+
+// do calls fn unless a call with the same key is in flight, in which case
+// it waits for and returns its result instead. A waiter whose own ctx is
+// still alive calls fn again if the shared call was aborted by its
+// caller's context.
+func (g *group) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	for {
+		g.mu.Lock()
+		if g.calls == nil {
+			g.calls = make(map[string]*call)
+		}
+		if cl, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			testHookGroupWait()
+			select {
+			case <-cl.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if (cl.err == context.Canceled || cl.err == context.DeadlineExceeded) && ctx.Err() == nil {
+				continue
+			}
+			return cl.val, cl.err
+		}
+
+		cl := &call{done: make(chan struct{})}
+		g.calls[key] = cl
+		g.mu.Unlock()
+
+		cl.val, cl.err = fn()
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(cl.done)
+
+		return cl.val, cl.err
+	}
+}
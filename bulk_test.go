@@ -0,0 +1,142 @@
+package claquete
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetSchedules(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/programacao/656/") {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var progress []int
+	c := NewClaquete(SiteURL(ts.URL), Workers(2), OnProgress(func(done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if total != 2 {
+			t.Errorf("expected total 2, got %d", total)
+		}
+		progress = append(progress, done)
+	}))
+
+	ids := []int{656, 656, 1, 656}
+	results, err := c.GetSchedules(ids)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if len(results) != len(ids) {
+		t.Fatalf("expected %d results, got %d", len(ids), len(results))
+	}
+	for i, r := range results {
+		if r.CinemaID != ids[i] {
+			t.Fatalf("expected cinema %d at %d, got %d", ids[i], i, r.CinemaID)
+		}
+		if r.CinemaID == 1 {
+			if r.Err == nil || r.Schedule != nil {
+				t.Fatalf("expected error for cinema 1, got %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil || r.Schedule != results[0].Schedule {
+			t.Fatalf("expected shared schedule at %d, got %+v", i, r)
+		}
+	}
+
+	if hits != 1 {
+		t.Fatalf("expected 1 request for cinema 656, got %d", hits)
+	}
+	if len(progress) != 2 || progress[1] != 2 {
+		t.Fatalf("unexpected progress %v", progress)
+	}
+}
+
+func TestGetMoviesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := NewClaquete().GetMoviesContext(ctx, []int{8427, 8600})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, but got: %v", err)
+	}
+	for _, r := range results {
+		if r.Err != context.Canceled {
+			t.Fatalf("expected context.Canceled, but got: %v", r.Err)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var g group
+	var calls int32
+	started := make(chan struct{})
+	ready := make(chan struct{})
+	release := make(chan struct{})
+
+	fn := func() (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return 42, nil
+	}
+	do := func() {
+		v, err := g.do(context.Background(), "key", fn)
+		if err != nil || v.(int) != 42 {
+			t.Errorf("unexpected result %v, %v", v, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		do()
+	}()
+	<-started
+
+	// The call is in flight until release is closed, so every waiter
+	// joins it instead of making its own
+	testHookGroupWait = func() { ready <- struct{}{} }
+	defer func() { testHookGroupWait = func() {} }()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			do()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-ready
+	}
+
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
@@ -0,0 +1,142 @@
+package claquete
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/claqueteapi/httpcache"
+)
+
+// Endpoints of the website used to configure cache TTLs.
+const (
+	// EndpointSchedule is the cinema program page
+	EndpointSchedule = "/programacao/"
+	// EndpointMovie is the movie page
+	EndpointMovie = "/filmes/filme.php"
+	// EndpointHeadlines is the news listing page, also used for releases
+	EndpointHeadlines = "/noticias.html"
+	// EndpointNews is the news page
+	EndpointNews = "/noticia/"
+	// EndpointSearch is the search page
+	EndpointSearch = "/busca.html"
+	// EndpointCalendar is the release calendar AJAX endpoint
+	EndpointCalendar = "/lib/ajax/ajax.calendario.php"
+	// EndpointCinemas is the cinemas of a city AJAX endpoint
+	EndpointCinemas = "/lib/ajax/ajax.escolherCinema_load.php"
+	// EndpointCinemaMovies is the now playing in a cinema AJAX endpoint
+	EndpointCinemaMovies = "/lib/ajax/ajax.escolherFilme.php"
+	// EndpointCityMovies is the now playing in a city AJAX endpoint
+	EndpointCityMovies = "/lib/ajax/ajax.escolherFilme_cidade.php"
+)
+
+// stateCookie is the session cookie in which the website keeps the
+// federative unit selected with escolherEstados.php.
+const stateCookie = "uf"
+
+var (
+	// DefaultCacheTTLs are the TTLs used by ResponseCache for endpoints
+	// without one set with CacheTTL. Responses of endpoints depending on
+	// the selected federative unit, like the cinemas and now playing in a
+	// city, are cached by federative unit, see cacheKey.
+	DefaultCacheTTLs = map[string]time.Duration{
+		EndpointSchedule:     15 * time.Minute,
+		EndpointMovie:        24 * time.Hour,
+		EndpointHeadlines:    30 * time.Minute,
+		EndpointNews:         24 * time.Hour,
+		EndpointSearch:       time.Hour,
+		EndpointCalendar:     6 * time.Hour,
+		EndpointCinemas:      24 * time.Hour,
+		EndpointCinemaMovies: 15 * time.Minute,
+		EndpointCityMovies:   15 * time.Minute,
+	}
+)
+
+// ResponseCache makes the Claquete keep responses in cache, both pages
+// and AJAX endpoints, for the TTLs in DefaultCacheTTLs.
+func ResponseCache(cache httpcache.Cache) func(*Claquete) {
+	return func(c *Claquete) {
+		c.cache = cache
+	}
+}
+
+// CacheTTL overrides the cache TTL of the given endpoint, one of the
+// Endpoint constants. A zero TTL disables caching for it.
+func CacheTTL(endpoint string, ttl time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		if c.cacheTTLs == nil {
+			c.cacheTTLs = make(map[string]time.Duration)
+		}
+		c.cacheTTLs[endpoint] = ttl
+	}
+}
+
+// StaleIfError makes the Claquete serve expired cached responses when the
+// website fails or can't be reached.
+func StaleIfError() func(*Claquete) {
+	return func(c *Claquete) {
+		c.staleIfError = true
+	}
+}
+
+// cacheTransport wraps rt with the configured cache, if any.
+func (c *Claquete) cacheTransport(rt http.RoundTripper) http.RoundTripper {
+	if c.cache == nil {
+		return rt
+	}
+	return &httpcache.Transport{
+		Cache:        c.cache,
+		Base:         rt,
+		TTL:          c.cacheTTL,
+		Key:          cacheKey,
+		StaleIfError: c.staleIfError,
+	}
+}
+
+// cacheTTL returns the TTL of the endpoint with the longest match
+// with the request path.
+func (c *Claquete) cacheTTL(req *http.Request) time.Duration {
+	var endpoint string
+	for e := range DefaultCacheTTLs {
+		if strings.HasPrefix(req.URL.Path, e) && len(e) > len(endpoint) {
+			endpoint = e
+		}
+	}
+	for e := range c.cacheTTLs {
+		if strings.HasPrefix(req.URL.Path, e) && len(e) > len(endpoint) {
+			endpoint = e
+		}
+	}
+	if ttl, ok := c.cacheTTLs[endpoint]; ok {
+		return ttl
+	}
+	return DefaultCacheTTLs[endpoint]
+}
+
+// cacheKey builds the key of a request with its form values sorted, since
+// colly encodes them in map order, and without the mouse position sent
+// along with search queries. Requests made with a federative unit selected
+// are told apart by it, since the website answers them for that unit only.
+func cacheKey(req *http.Request, body []byte) string {
+	key := formKey(req, body)
+	if cookie, err := req.Cookie(stateCookie); err == nil && cookie.Value != "" {
+		key += " " + stateCookie + "=" + cookie.Value
+	}
+	return key
+}
+
+func formKey(req *http.Request, body []byte) string {
+	if len(body) == 0 {
+		return httpcache.DefaultKey(req, body)
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return httpcache.DefaultKey(req, body)
+	}
+	if strings.HasPrefix(req.URL.Path, EndpointSearch) {
+		values.Del("x")
+		values.Del("y")
+	}
+	return httpcache.DefaultKey(req, []byte(values.Encode()))
+}
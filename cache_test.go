@@ -0,0 +1,80 @@
+package claquete
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/claqueteapi/httpcache"
+)
+
+func TestResponseCache(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	c := NewClaquete(SiteURL(ts.URL), ResponseCache(httpcache.NewLRU(10)))
+	for i := 0; i < 3; i++ {
+		sched, err := c.GetSchedule(656)
+		if err != nil {
+			t.Fatalf("expected no error, but got error: %s", err.Error())
+		}
+		if sched.Cinema.Name != "Cinemais Montes Claros" {
+			t.Fatalf("expected Cinemais Montes Claros, got %s", sched.Cinema.Name)
+		}
+	}
+
+	if hits != 1 {
+		t.Fatalf("expected 1 request to server, got %d", hits)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := NewClaquete(CacheTTL(EndpointMovie, time.Minute), CacheTTL(EndpointSchedule, 0))
+
+	cases := map[string]time.Duration{
+		"/filmes/filme.php?cf=8427":               time.Minute,
+		"/programacao/656/cinema-656.html":        0,
+		"/lib/ajax/ajax.escolherFilme.php":        DefaultCacheTTLs[EndpointCinemaMovies],
+		"/lib/ajax/ajax.escolherFilme_cidade.php": DefaultCacheTTLs[EndpointCityMovies],
+		"/lib/ajax/ajax.escolherEstados.php":      0,
+	}
+
+	for path, expected := range cases {
+		req, _ := http.NewRequest(http.MethodGet, BaseURL+path, nil)
+		if actual := c.cacheTTL(req); actual != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, path, actual)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, BaseURL+EndpointSearch, nil)
+	a := cacheKey(req, []byte("query=vingadores&x=1&y=20"))
+	b := cacheKey(req, []byte("y=3&query=vingadores&x=12"))
+	if a != b {
+		t.Fatalf("expected %s, got %s", a, b)
+	}
+
+	// Cinemas of the same city name in different federative units
+	cinemas := func(fu string) string {
+		req, _ := http.NewRequest(http.MethodPost, BaseURL+EndpointCinemas, nil)
+		req.AddCookie(&http.Cookie{Name: stateCookie, Value: fu})
+		return cacheKey(req, []byte("cidade=Santa+Luzia"))
+	}
+	if mg, pb := cinemas(MG), cinemas(PB); mg == pb {
+		t.Fatalf("expected different keys by federative unit, got %s", mg)
+	}
+}
@@ -1,7 +1,9 @@
 package claquete
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"io"
 	"strconv"
 	"time"
 
@@ -20,26 +22,98 @@ type (
 
 	// Calendar struct
 	Calendar struct {
+		c     *Claquete
 		Month time.Month    `json:"month"`
 		Year  int           `json:"year"`
 		Weeks []ReleaseWeek `json:"weeks"`
+		// Diagnostics lists the weeks and movies that couldn't be fully
+		// parsed.
+		Diagnostics ParseErrors `json:"diagnostics,omitempty"`
 	}
 )
 
 // GetCalendar retrieves calendar for current month and year
-func GetCalendar() (*Calendar, error) {
-	loc, _ := time.LoadLocation("America/Sao_Paulo")
+func GetCalendar(options ...Options) (*Calendar, error) {
+	return GetCalendarContext(context.Background(), options...)
+}
+
+// GetCalendarContext is like GetCalendar but aborts when ctx is done.
+func GetCalendarContext(ctx context.Context, options ...Options) (*Calendar, error) {
+	return NewClaquete(options...).GetCalendarContext(ctx)
+}
+
+// GetCalendarAt retrieves calendar for the given month and year
+func GetCalendarAt(month time.Month, year int, options ...Options) (*Calendar, error) {
+	return GetCalendarAtContext(context.Background(), month, year, options...)
+}
+
+// GetCalendarAtContext is like GetCalendarAt but aborts when ctx is done.
+func GetCalendarAtContext(ctx context.Context, month time.Month, year int, options ...Options) (*Calendar, error) {
+	return NewClaquete(options...).GetCalendarAtContext(ctx, month, year)
+}
+
+// GetCalendar retrieves calendar for current month and year
+func (c *Claquete) GetCalendar() (*Calendar, error) {
+	return c.GetCalendarContext(context.Background())
+}
+
+// GetCalendarContext is like GetCalendar but aborts when ctx is done.
+func (c *Claquete) GetCalendarContext(ctx context.Context) (*Calendar, error) {
+	loc := util.LoadLocation("America/Sao_Paulo")
 	now := time.Now().In(loc)
-	return getCalendar(now.Month(), now.Year())
+	return c.GetCalendarAtContext(ctx, now.Month(), now.Year())
 }
 
 // GetCalendarAt retrieves calendar for the given month and year
-func GetCalendarAt(month time.Month, year int) (*Calendar, error) {
-	return getCalendar(month, year)
+func (c *Claquete) GetCalendarAt(month time.Month, year int) (*Calendar, error) {
+	return c.GetCalendarAtContext(context.Background(), month, year)
 }
 
-func getCalendar(month time.Month, year int) (*Calendar, error) {
-	loc, _ := time.LoadLocation("America/Sao_Paulo")
+// GetCalendarAtContext is like GetCalendarAt but aborts when ctx is done.
+func (c *Claquete) GetCalendarAtContext(ctx context.Context, month time.Month, year int) (*Calendar, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var result *Calendar
+	var err error
+
+	collector := c.collect(ctx)
+	collector.OnResponse(func(r *colly.Response) {
+		result, err = ParseCalendar(bytes.NewReader(r.Body), month, year)
+	})
+
+	errPost := collector.Post(c.ajax("calendario.php"), map[string]string{
+		"ano": strconv.Itoa(year),
+		"mes": strconv.Itoa(int(month)),
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errPost != nil {
+		return nil, errPost
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.c = c
+
+	return result, nil
+}
+
+// ParseCalendar parses a release calendar read from r. The calendar page
+// doesn't carry the month and year it refers to, so they must be the
+// ones used to request it.
+//
+// Weeks or movies that couldn't be fully parsed are kept and reported in
+// the calendar Diagnostics.
+func ParseCalendar(r io.Reader, month time.Month, year int) (*Calendar, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := util.LoadLocation("America/Sao_Paulo")
 	result := &Calendar{
 		Month: month,
 		Year:  year,
@@ -47,79 +121,81 @@ func getCalendar(month time.Month, year int) (*Calendar, error) {
 
 	var week ReleaseWeek
 
-	c := NewClaquete()
-	c.collector.OnHTML("*", func(e *colly.HTMLElement) {
-		e.DOM.Each(func(i int, s *goquery.Selection) {
-			class := s.AttrOr("class", "")
-			if class != "" {
-				if s.Is("div") && class == "cxsem" {
-					week = ReleaseWeek{}
-					d := s.Find("p").Text()
-					day, err := strconv.Atoi(d)
-					if err != nil {
-						fmt.Printf("couldn't convert day to integer in text %s", d)
-					} else {
-						week.Date = time.Date(result.Year, result.Month, day, 0, 0, 0, 0, loc)
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		class := s.AttrOr("class", "")
+		if class != "" {
+			if s.Is(selCalendarWeek) {
+				week = ReleaseWeek{}
+				d := s.Find(selCalendarDay).Text()
+				day, err := strconv.Atoi(d)
+				if err != nil {
+					result.Diagnostics.add("", selCalendarWeek+" "+selCalendarDay, d, err)
+				} else {
+					week.Date = time.Date(result.Year, result.Month, day, 0, 0, 0, 0, loc)
+				}
+			} else if s.Is(selCalendarPosters) {
+				s.Children().Each(func(j int, ss *goquery.Selection) {
+					movie := Movie{
+						Title:  ss.Find("ul > li > p").Text(),
+						Poster: ss.Find("img").AttrOr("src", ""),
+						Page:   ss.Find(selCalendarSynopsis).Parent().AttrOr("href", ""),
 					}
-				} else if s.Is("ul") && class == "posters" {
-					s.Children().Each(func(j int, ss *goquery.Selection) {
-						movie := Movie{
-							Title:  ss.Find("ul > li > p").Text(),
-							Poster: ss.Find("img").AttrOr("src", ""),
-							Page:   ss.Find("div[data-hint=\"Sinopse\"]").Parent().AttrOr("href", ""),
+					if movie.Page != "" {
+						slug, err := movieutil.SlugFromURLString(movie.Page)
+						if err != nil {
+							result.Diagnostics.add("", selCalendarPosters+" a", movie.Page, err)
+							// Fallback to our slug creation function
+							movie.Slug = util.CreateSlug(movie.Title)
+						} else {
+							movie.Slug = slug
 						}
-						if movie.Page != "" {
-							slug, err := movieutil.SlugFromURLString(movie.Page)
-							if err != nil {
-								fmt.Println(err)
-								// Fallback to our slug creation function
-								movie.Slug = util.CreateSlug(movie.Title)
-							} else {
-								movie.Slug = slug
-							}
+					}
+					ss.Find("a").EachWithBreak(func(i int, s *goquery.Selection) bool {
+						ID, err := movieutil.IDFromURLString(s.AttrOr("href", ""))
+						if err == nil {
+							movie.ID = ID
+							return false
 						}
-						ss.Find("a").EachWithBreak(func(i int, s *goquery.Selection) bool {
-							ID, err := movieutil.IDFromURLString(s.AttrOr("href", ""))
-							if err == nil {
-								movie.ID = ID
-								return false
-							}
-							return true
-						})
-						week.Movies = append(week.Movies, movie)
+						return true
 					})
-					result.Weeks = append(result.Weeks, week)
-				}
+					week.Movies = append(week.Movies, movie)
+				})
+				result.Weeks = append(result.Weeks, week)
 			}
-		})
-	})
-
-	err := c.collector.Post(BaseAJAX+"calendario.php", map[string]string{
-		"ano": strconv.Itoa(year),
-		"mes": strconv.Itoa(int(month)),
+		}
 	})
 
-	return result, err
+	return result, nil
 }
 
 // NextMonth get next month calendar from the current one
 func (cal *Calendar) NextMonth() (*Calendar, error) {
+	return cal.NextMonthContext(context.Background())
+}
+
+// NextMonthContext is like NextMonth but aborts when ctx is done.
+func (cal *Calendar) NextMonthContext(ctx context.Context) (*Calendar, error) {
 	month, year := cal.Month, cal.Year
 	month++
 	if month > time.December {
 		month = time.January
 		year++
 	}
-	return getCalendar(month, year)
+	return clientOrDefault(cal.c).GetCalendarAtContext(ctx, month, year)
 }
 
 // PrevMonth get previous month calendar from the current one
 func (cal *Calendar) PrevMonth() (*Calendar, error) {
+	return cal.PrevMonthContext(context.Background())
+}
+
+// PrevMonthContext is like PrevMonth but aborts when ctx is done.
+func (cal *Calendar) PrevMonthContext(ctx context.Context) (*Calendar, error) {
 	month, year := cal.Month, cal.Year
 	month--
 	if month < time.January {
 		month = time.December
 		year--
 	}
-	return getCalendar(month, year)
+	return clientOrDefault(cal.c).GetCalendarAtContext(ctx, month, year)
 }
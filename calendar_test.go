@@ -1,12 +1,13 @@
 package claquete
 
 import (
+	"os"
 	"testing"
 	"time"
 )
 
 func TestGetCalendar(t *testing.T) {
-	calendar, err := GetCalendar()
+	calendar, err := GetCalendar(fixtures(t))
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -28,3 +29,34 @@ func TestGetCalendar(t *testing.T) {
 			tt.Month(), tt.Year(), calendar.Month, calendar.Year)
 	}
 }
+
+func TestParseCalendar(t *testing.T) {
+	f, err := os.Open("testdata/calendario.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	calendar, err := ParseCalendar(f, time.January, 2019)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if len(calendar.Weeks) != 2 {
+		t.Fatalf("expected 2 weeks, got %d", len(calendar.Weeks))
+	}
+
+	week := calendar.Weeks[0]
+	if week.Date.Day() != 3 || week.Date.Month() != time.January || week.Date.Year() != 2019 {
+		t.Fatalf("expected 03/01/2019, got %s", week.Date)
+	}
+
+	if len(week.Movies) != 2 {
+		t.Fatalf("expected 2 movies, got %d", len(week.Movies))
+	}
+
+	movie := week.Movies[0]
+	if movie.ID != 8427 || movie.Slug != "o-retorno-de-mary-poppins" || movie.Title != "O Retorno de Mary Poppins" {
+		t.Fatalf("unexpected movie %+v", movie)
+	}
+}
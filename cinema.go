@@ -1,6 +1,7 @@
 package claquete
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -18,14 +19,38 @@ type (
 		ID          int    `json:"id"`
 		Name        string `json:"name"`
 		AddressLine string `json:"address_line"`
-		TimeZone    string `json:"time_zone"`
+		// Address is the address line parsed in parts.
+		Address Address `json:"address"`
+		// Coordinates are the location of the cinema in the map, when
+		// known.
+		Coordinates *Coordinates `json:"coordinates,omitempty"`
+		TimeZone    string       `json:"time_zone"`
 	}
 )
 
 // GetCinema TODO
-func GetCinema(id int) (*Cinema, error) {
+func GetCinema(id int, options ...Options) (*Cinema, error) {
+	return GetCinemaContext(context.Background(), id, options...)
+}
+
+// GetCinemaContext is like GetCinema but aborts when ctx is done.
+func GetCinemaContext(ctx context.Context, id int, options ...Options) (*Cinema, error) {
+	return NewClaquete(options...).GetCinemaContext(ctx, id)
+}
+
+// GetCinema TODO
+func (c *Claquete) GetCinema(id int) (*Cinema, error) {
+	return c.GetCinemaContext(context.Background(), id)
+}
+
+// GetCinemaContext is like GetCinema but aborts when ctx is done.
+func (c *Claquete) GetCinemaContext(ctx context.Context, id int) (*Cinema, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	if id < 0 {
-		return nil, errors.New("invalid ID")
+		return nil, errors.Wrapf(ErrInvalidArgument, "cinema ID %d", id)
 	}
 
 	idStr := strconv.Itoa(id)
@@ -33,14 +58,11 @@ func GetCinema(id int) (*Cinema, error) {
 		return nil, fmt.Errorf("couldn't create ID from integer %d", id)
 	}
 
-	// Uses cinema id in container class
-	container := fmt.Sprintf("body > div.conteudo > div.progrb > div.cinema%d", id)
-
 	var result *Cinema
 	var err1 error
 
-	c := NewClaquete()
-	c.collector.OnHTML(container, func(e *colly.HTMLElement) {
+	collector := c.collect(ctx)
+	collector.OnHTML(selScheduleCinema(id), func(e *colly.HTMLElement) {
 		cinema, err := parseCinema(e.DOM)
 		if err != nil {
 			err1 = err
@@ -51,58 +73,88 @@ func GetCinema(id int) (*Cinema, error) {
 		result = cinema
 	})
 	// Try to retrieve time zone
-	c.collector.OnHTML("body > div.conteudo > div.progrb > div:nth-child(1) > p", func(e *colly.HTMLElement) {
+	collector.OnHTML(selSchedule+" > "+selScheduleState, func(e *colly.HTMLElement) {
 		text := strings.TrimSpace(strings.Replace(e.Text, "por cinemas em", "", -1))
-		if text != "" { // Expected state name
-			result.TimeZone = getTimeZone(text)
+		if text != "" && result != nil { // Expected state name
+			result.TimeZone = getCityTimeZone(text, result.Address.City)
 		}
 	})
 
-	c.collector.OnScraped(func(*colly.Response) {
-		if result == nil {
-			err1 = errors.New("cinema not found")
+	collector.OnScraped(func(*colly.Response) {
+		if result == nil && err1 == nil {
+			err1 = errors.Wrapf(ErrNotFound, "cinema %d", id)
 		}
 	})
 
 	// The request is successful with any string
 	// followed by .html
-	u := fmt.Sprintf("%s/programacao/%s/.html", BaseURL, idStr)
+	u := c.url(fmt.Sprintf("/programacao/%s/.html", idStr))
 
-	err := c.collector.Visit(u)
-	if err == nil && err1 != nil {
-		err = err1
+	err := collector.Visit(u)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return result, err
+	return result, err1
 }
 
 // GetNowPlaying retrieves now playing movies for Cinema.
 // To get additional movie metadata use the GetMovie(int)
 // function passing the retrieved movie id.
 func (c *Cinema) GetNowPlaying() ([]Movie, error) {
+	return c.GetNowPlayingContext(context.Background())
+}
+
+// GetNowPlayingContext is like GetNowPlaying but aborts when ctx is done.
+func (c *Cinema) GetNowPlayingContext(ctx context.Context) ([]Movie, error) {
 	params := map[string]string{"cinema": strconv.Itoa(c.ID)}
-	return getNowPlayingList(c.c.collector, "escolherFilme.php", params)
+	return clientOrDefault(c.c).getNowPlayingList(ctx, "", "escolherFilme.php", params)
 }
 
 // GetCinemas TODO
 func (c *Claquete) GetCinemas() ([]Cinema, error) {
-	return getCinemas(c)
+	return c.GetCinemasContext(context.Background())
 }
 
-func getCinemas(c *Claquete) ([]Cinema, error) {
-	var result []Cinema
-	var err error
+// GetCinemasContext is like GetCinemas but aborts when ctx is done.
+func (c *Claquete) GetCinemasContext(ctx context.Context) ([]Cinema, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
 
 	if c.city == "" {
-		return nil, errors.New("city was not specified")
+		return nil, errors.Wrap(ErrInvalidArgument, "city was not specified")
+	}
+
+	return c.getCinemas(ctx, c.fu, c.city)
+}
+
+// getCinemas retrieves the cinemas of the given city in the federative
+// unit fu.
+func (c *Claquete) getCinemas(ctx context.Context, fu, city string) ([]Cinema, error) {
+	var result []Cinema
+	var errs ParseErrors
+
+	collector, err := c.collectState(ctx, fu)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	c.collector.OnHTML("option", func(e *colly.HTMLElement) {
+	collector.OnHTML("option", func(e *colly.HTMLElement) {
 		value := e.Attr("value")
+		if value == "" {
+			return
+		}
 
 		ID, err := strconv.Atoi(value)
 		if err != nil {
-			err = errors.Wrapf(err, "conversion value %s to integer failed", value)
+			errs.add(e.Request.URL.String(), "option", value, err)
 			return
 		}
 		if ID != 0 {
@@ -114,34 +166,54 @@ func getCinemas(c *Claquete) ([]Cinema, error) {
 		}
 	})
 
-	u := fmt.Sprintf("%sescolherCinema_load.php", BaseAJAX)
-	err = c.collector.Post(u, map[string]string{"cidade": c.city})
-	return result, err
+	err = collector.Post(c.ajax("escolherCinema_load.php"), map[string]string{"cidade": city})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.report(errs)
+	return result, nil
 }
 
 // GetCinemas retrieve cinema list
-func GetCinemas(fu, city string) ([]Cinema, error) {
-	return getCinemas(NewClaquete(
-		FederativeUnit(fu),
-		CityName(city),
-	))
+func GetCinemas(fu, city string, options ...Options) ([]Cinema, error) {
+	return GetCinemasContext(context.Background(), fu, city, options...)
+}
+
+// GetCinemasContext is like GetCinemas but aborts when ctx is done.
+func GetCinemasContext(ctx context.Context, fu, city string, options ...Options) ([]Cinema, error) {
+	options = append([]Options{FederativeUnit(fu), CityName(city)}, options...)
+	return NewClaquete(options...).GetCinemasContext(ctx)
 }
 
 // GetSchedule ...
 func (c *Cinema) GetSchedule() (*Schedule, error) {
-	return GetSchedule(c.ID)
+	return c.GetScheduleContext(context.Background())
+}
+
+// GetScheduleContext is like GetSchedule but aborts when ctx is done.
+func (c *Cinema) GetScheduleContext(ctx context.Context) (*Schedule, error) {
+	return clientOrDefault(c.c).GetScheduleContext(ctx, c.ID)
 }
 
 func parseCinema(s *goquery.Selection) (*Cinema, error) {
-	addressLine := strings.TrimSpace(s.Find("div > div > span").Text())
+	address := s.Find(selCinemaAddress).First()
+	addressLine := strings.TrimSpace(address.Text())
 	if addressLine != "" {
 		addressLine = strings.Replace(addressLine, "\n", "", -1)
 		addressLine = strings.Replace(addressLine, "(mapa)", "", -1)
 	}
 	result := &Cinema{
-		Name:        strings.TrimSpace(s.Find("div > div > div.ttcine > h2").Text()),
+		Name:        strings.TrimSpace(s.Find(selCinemaName).Text()),
 		AddressLine: strings.TrimSpace(addressLine),
 	}
+	result.Address = parseAddress(result.AddressLine)
+	// The map link points to the coordinates of the cinema
+	if coordinates, ok := parseMapLink(address.Find("a").AttrOr("href", "")); ok {
+		result.Coordinates = coordinates
+	}
 	return result, nil
 }
 
@@ -1,11 +1,13 @@
 package claquete
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
 func TestGetCinema(t *testing.T) {
-	cinema, err := GetCinema(656) // Cinemais Montes Claros
+	cinema, err := GetCinema(656, fixtures(t)) // Cinemais Montes Claros
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -16,7 +18,7 @@ func TestGetCinema(t *testing.T) {
 }
 
 func TestGetCinemas(t *testing.T) {
-	cinemas, err := GetCinemas(MG, "Montes Claros")
+	cinemas, err := GetCinemas(MG, "Montes Claros", fixtures(t))
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -26,3 +28,27 @@ func TestGetCinemas(t *testing.T) {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
 }
+
+func TestGetCinemasDiagnostics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<option value="">Selecione</option>
+			<option value="656">Cinemais Montes Claros</option>
+			<option value="fechado">Cine Fechado</option>`))
+	}))
+	defer ts.Close()
+
+	var diagnostics ParseErrors
+	cinemas, err := GetCinemas(MG, "Montes Claros", SiteURL(ts.URL), OnDiagnostics(func(errs ParseErrors) {
+		diagnostics = append(diagnostics, errs...)
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cinemas) != 1 || cinemas[0].ID != 656 {
+		t.Fatalf("expected cinema 656, got %+v", cinemas)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Text != "fechado" {
+		t.Fatalf("expected a diagnostic for fechado, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,135 @@
+package claquete
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// CitySchedule is the schedule of every cinema in a city.
+type CitySchedule struct {
+	City    City     `json:"city"`
+	Cinemas []Cinema `json:"cinemas"`
+	// ByCinema indexes the schedules by cinema ID. Cinemas whose schedule
+	// couldn't be retrieved are left out and reported in Errors.
+	ByCinema map[int]*Schedule `json:"by_cinema"`
+	// ByMovie indexes the sessions of every cinema by movie ID, ordered by
+	// start time.
+	ByMovie map[int]Sessions `json:"by_movie"`
+	// Errors holds the error of each cinema whose schedule couldn't be
+	// retrieved.
+	Errors map[int]error `json:"-"`
+}
+
+// GetCitySchedule retrieves the schedule of every cinema in a city.
+func GetCitySchedule(fu, city string, options ...Options) (*CitySchedule, error) {
+	return GetCityScheduleContext(context.Background(), fu, city, options...)
+}
+
+// GetCityScheduleContext is like GetCitySchedule but aborts when ctx is
+// done.
+func GetCityScheduleContext(ctx context.Context, fu, city string, options ...Options) (*CitySchedule, error) {
+	options = append([]Options{FederativeUnit(fu), CityName(city)}, options...)
+	return NewClaquete(options...).GetCityScheduleContext(ctx)
+}
+
+// GetCitySchedule retrieves the schedule of every cinema in the city of
+// the Claquete.
+func (c *Claquete) GetCitySchedule() (*CitySchedule, error) {
+	return c.GetCityScheduleContext(context.Background())
+}
+
+// GetCityScheduleContext is like GetCitySchedule but aborts when ctx is
+// done.
+func (c *Claquete) GetCityScheduleContext(ctx context.Context) (*CitySchedule, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if c.city == "" {
+		return nil, errors.Wrap(ErrInvalidArgument, "city was not specified")
+	}
+
+	city := City{
+		c:     c,
+		State: State{FU: c.fu, Name: getStateName(c.fu)},
+		Name:  c.city,
+	}
+	return city.GetScheduleContext(ctx)
+}
+
+// GetSchedule retrieves the schedule of every cinema in the city
+// concurrently, see Workers. Cinemas whose schedule couldn't be retrieved
+// are reported in the result Errors, failing only when all of them fail.
+func (c *City) GetSchedule() (*CitySchedule, error) {
+	return c.GetScheduleContext(context.Background())
+}
+
+// GetScheduleContext is like GetSchedule but aborts when ctx is done.
+func (c *City) GetScheduleContext(ctx context.Context) (*CitySchedule, error) {
+	client := clientOrDefault(c.c)
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	cinemas, err := client.getCinemas(ctx, c.State.FU, c.Name)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if cinemas == nil && err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(cinemas))
+	for i, cinema := range cinemas {
+		ids[i] = cinema.ID
+	}
+	schedules, err := client.GetSchedulesContext(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CitySchedule{
+		City:     *c,
+		Cinemas:  cinemas,
+		ByCinema: make(map[int]*Schedule),
+		ByMovie:  make(map[int]Sessions),
+		Errors:   make(map[int]error),
+	}
+	for _, r := range schedules {
+		if r.Err != nil {
+			result.Errors[r.CinemaID] = r.Err
+			continue
+		}
+		result.ByCinema[r.CinemaID] = r.Schedule
+		for _, s := range r.Schedule.Sessions {
+			result.ByMovie[s.MovieID] = append(result.ByMovie[s.MovieID], s)
+		}
+	}
+	for id, sessions := range result.ByMovie {
+		result.ByMovie[id] = sessions.Sort(ByStartTime, ByCinema, ByRoom)
+	}
+
+	if len(cinemas) > 0 && len(result.ByCinema) == 0 {
+		return nil, errors.Wrapf(schedules[0].Err, "no schedule of the %d cinemas in %s", len(cinemas), c.Name)
+	}
+
+	return result, nil
+}
+
+// Sessions returns the sessions of every cinema, ordered by cinema ID, to
+// be queried.
+func (cs *CitySchedule) Sessions() Sessions {
+	ids := make([]int, 0, len(cs.ByCinema))
+	for id := range cs.ByCinema {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	schedules := make([]*Schedule, len(ids))
+	for i, id := range ids {
+		schedules[i] = cs.ByCinema[id]
+	}
+	return MergeSessions(schedules...)
+}
@@ -0,0 +1,215 @@
+package claquete_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+	"github.com/dsbezerra/claqueteapi/claquetetest"
+	"github.com/dsbezerra/claqueteapi/httpcache"
+)
+
+func TestCitySchedule(t *testing.T) {
+	s := claquetetest.NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	at := func(hours, minutes int) *time.Time {
+		t := today.Add(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute)
+		return &t
+	}
+
+	city := claquete.City{Name: "Montes Claros", State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais}}
+	cinemais := claquete.Cinema{ID: 656, Name: "Cinemais Montes Claros"}
+	moviecom := claquete.Cinema{ID: 657, Name: "Moviecom Montes Claros"}
+	closed := claquete.Cinema{ID: 658, Name: "Cine Fechado"}
+	s.AddCinema(city, cinemais)
+	s.AddCinema(city, moviecom)
+	s.AddCinema(city, closed)
+
+	avengers := claquete.Session{MovieID: 8600, MovieTitle: "Vingadores: Ultimato", Version: claquete.VersionSubtitled, Room: claquete.Room{Number: 1}}
+	mary := claquete.Session{MovieID: 8427, MovieTitle: "O Retorno de Mary Poppins", Version: claquete.VersionDubbed, Room: claquete.Room{Number: 2}}
+	session := func(s claquete.Session, cinema, hours, minutes int) claquete.Session {
+		s.CinemaID = cinema
+		s.StartTime = at(hours, minutes)
+		return s
+	}
+	s.AddSchedule(claquete.Schedule{Cinema: &cinemais, Sessions: []claquete.Session{
+		session(avengers, 656, 20, 15),
+		session(mary, 656, 14, 0),
+	}})
+	s.AddSchedule(claquete.Schedule{Cinema: &moviecom, Sessions: []claquete.Session{
+		session(avengers, 657, 19, 0),
+	}})
+
+	cs, err := claquete.GetCitySchedule(claquete.MG, "Montes Claros", s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if len(cs.Cinemas) != 3 || len(cs.ByCinema) != 2 {
+		t.Fatalf("expected 2 of 3 schedules, got %d of %d", len(cs.ByCinema), len(cs.Cinemas))
+	}
+	if err := cs.Errors[658]; !errors.Is(err, claquete.ErrNotFound) {
+		t.Fatalf("expected %s for the closed cinema, got %v", claquete.ErrNotFound, err)
+	}
+
+	playing := cs.ByMovie[8600]
+	if len(playing) != 2 || playing[0].CinemaID != 657 || playing[1].CinemaID != 656 {
+		t.Fatalf("unexpected sessions %+v", playing)
+	}
+
+	tonight := cs.Sessions().Filter(claquete.WithMovie(8600), claquete.OnDay(today), claquete.TimeOfDay(20*time.Hour, 24*time.Hour))
+	if len(tonight) != 1 || tonight[0].CinemaID != 656 {
+		t.Fatalf("unexpected sessions %+v", tonight)
+	}
+
+	cities, err := claquete.GetCities(claquete.MG, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cities) != 1 || cities[0].State.FU != claquete.MG {
+		t.Fatalf("unexpected cities %+v", cities)
+	}
+	cs, err = cities[0].GetSchedule()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cs.ByMovie) != 2 || len(cs.ByCinema) != 2 {
+		t.Fatalf("unexpected city schedule %+v", cs)
+	}
+}
+
+func TestCitiesConcurrentStates(t *testing.T) {
+	s := claquetetest.NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 20, 0, 0, 0, loc)
+
+	// Santa Luzia is a city in both Minas Gerais and Paraíba
+	mg := claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais}}
+	pb := claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.PB, Name: claquete.Paraiba}}
+	for id, city := range map[int]claquete.City{700: mg, 800: pb} {
+		cinema := claquete.Cinema{ID: id, Name: "Cine Santa Luzia " + city.State.FU}
+		s.AddCinema(city, cinema)
+		s.AddSchedule(claquete.Schedule{Cinema: &cinema, Sessions: []claquete.Session{
+			{CinemaID: id, MovieID: 8600, MovieTitle: "Vingadores: Ultimato", Room: claquete.Room{Number: 1}, StartTime: &start},
+		}})
+	}
+
+	// A single client shared by every goroutine
+	client := claquete.NewClaquete(s.Options())
+	states, err := client.GetStates()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	expected := map[string]int{claquete.MG: 700, claquete.PB: 800}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		for _, state := range states {
+			id, ok := expected[state.FU]
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func(state claquete.State, id int) {
+				defer wg.Done()
+				cities, err := state.GetCities()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(cities) != 1 {
+					errs <- fmt.Errorf("expected 1 city in %s, got %+v", state.FU, cities)
+					return
+				}
+				cs, err := cities[0].GetSchedule()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(cs.Cinemas) != 1 || cs.Cinemas[0].ID != id {
+					errs <- fmt.Errorf("expected cinema %d in %s, got %+v", id, state.FU, cs.Cinemas)
+				}
+			}(state, id)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestCinemasCacheByState(t *testing.T) {
+	s := claquetetest.NewServer()
+	defer s.Close()
+
+	s.AddCinema(claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais}}, claquete.Cinema{ID: 700, Name: "Cine Santa Luzia MG"})
+	s.AddCinema(claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.PB, Name: claquete.Paraiba}}, claquete.Cinema{ID: 800, Name: "Cine Santa Luzia PB"})
+
+	cache := httpcache.NewLRU(10)
+	for i := 0; i < 2; i++ {
+		for fu, id := range map[string]int{claquete.MG: 700, claquete.PB: 800} {
+			cinemas, err := claquete.GetCinemas(fu, "Santa Luzia", s.Options(), claquete.ResponseCache(cache))
+			if err != nil {
+				t.Fatalf("expected no error, but got error: %s", err.Error())
+			}
+			if len(cinemas) != 1 || cinemas[0].ID != id {
+				t.Fatalf("expected cinema %d in %s, got %+v", id, fu, cinemas)
+			}
+		}
+	}
+}
+
+func TestCityNowPlayingByState(t *testing.T) {
+	s := claquetetest.NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 20, 0, 0, 0, loc)
+
+	// Santa Luzia in each state plays a different movie
+	mg := claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais}}
+	pb := claquete.City{Name: "Santa Luzia", State: claquete.State{FU: claquete.PB, Name: claquete.Paraiba}}
+	movies := map[string]claquete.Session{
+		claquete.MG: {CinemaID: 700, MovieID: 8600, MovieTitle: "Vingadores: Ultimato", StartTime: &start},
+		claquete.PB: {CinemaID: 800, MovieID: 8427, MovieTitle: "O Retorno de Mary Poppins", StartTime: &start},
+	}
+	for _, city := range []claquete.City{mg, pb} {
+		session := movies[city.State.FU]
+		cinema := claquete.Cinema{ID: session.CinemaID, Name: "Cine Santa Luzia " + city.State.FU}
+		s.AddCinema(city, cinema)
+		s.AddSchedule(claquete.Schedule{Cinema: &cinema, Sessions: []claquete.Session{session}})
+	}
+
+	cache := httpcache.NewLRU(10)
+	for i := 0; i < 2; i++ {
+		for fu, session := range movies {
+			cities, err := claquete.GetCities(fu, s.Options(), claquete.ResponseCache(cache))
+			if err != nil {
+				t.Fatalf("expected no error, but got error: %s", err.Error())
+			}
+			if len(cities) != 1 {
+				t.Fatalf("expected 1 city in %s, got %+v", fu, cities)
+			}
+			playing, err := cities[0].GetNowPlaying()
+			if err != nil {
+				t.Fatalf("expected no error, but got error: %s", err.Error())
+			}
+			if len(playing) != 1 || playing[0].ID != session.MovieID {
+				t.Fatalf("expected movie %d in %s, got %+v", session.MovieID, fu, playing)
+			}
+		}
+	}
+}
@@ -1,10 +1,18 @@
 package claquete
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/dsbezerra/claqueteapi/httpcache"
+	"github.com/dsbezerra/claqueteapi/httppolicy"
+	"github.com/dsbezerra/claqueteapi/httpreplay"
 	"github.com/dsbezerra/claqueteapi/movieutil"
 	"github.com/dsbezerra/claqueteapi/util"
 	"github.com/gocolly/colly"
@@ -23,63 +31,308 @@ type Options func(*Claquete)
 
 type (
 	// Claquete struct
+	//
+	// A Claquete is safe for concurrent use by multiple goroutines once
+	// created. Every operation scrapes with its own collector so callbacks
+	// never leak between calls, while cookies and transport are shared.
+	// Operations depending on the federative unit, which the website keeps
+	// in a session cookie, use cookies of their own instead.
 	Claquete struct {
-		fu        string
-		city      string
-		collector *colly.Collector
+		fu            string
+		city          string
+		baseURL       string
+		userAgent     string
+		timeout       time.Duration
+		tlsConfig     *tls.Config
+		base          http.RoundTripper
+		transport     http.RoundTripper
+		jar           *cookiejar.Jar
+		stateMu       sync.Mutex
+		stateJars     map[string]*stateJar
+		cache         httpcache.Cache
+		cacheTTLs     map[string]time.Duration
+		staleIfError  bool
+		policy        *httppolicy.Transport
+		site          http.RoundTripper
+		workers       int
+		progress      func(done, total int)
+		diagnostics   func(ParseErrors)
+		inflight      group
+		sessionBuffer time.Duration
+		record        string
+		replay        string
+		err           error
+	}
+
+	// stateJar holds cookies with a federative unit selected, see
+	// collectState.
+	stateJar struct {
+		once sync.Once
+		jar  *cookiejar.Jar
+		err  error
+	}
+
+	// contextTransport binds every outgoing request to a context.Context
+	// so in-flight requests are aborted as soon as it is done.
+	contextTransport struct {
+		ctx  context.Context
+		base http.RoundTripper
 	}
 )
 
 // NewClaquete creates a new Claquete instance
 func NewClaquete(options ...Options) *Claquete {
-	c := &Claquete{}
-	c.Init()
+	c := &Claquete{sessionBuffer: DefaultSessionBuffer}
 
 	for _, f := range options {
 		f(c)
 	}
 
-	if c.fu != "" {
-		// Make sure we got the cookies
-		c.GetCities()
-	}
+	c.Init()
 
 	return c
 }
 
 // Init initializes claquete's struct
 func (c *Claquete) Init() {
-	c.collector = colly.NewCollector(
-		colly.UserAgent(util.RandomUserAgent()),
+	if c.baseURL == "" {
+		c.baseURL = BaseURL
+	}
+	if c.userAgent == "" {
+		c.userAgent = util.RandomUserAgent()
+	}
+	c.site = c.policyTransport(c.roundTripper())
+	c.transport = c.cacheTransport(c.site)
+	c.jar, _ = cookiejar.New(nil)
+}
+
+// collect creates a collector for a single operation. Its requests are
+// bound to ctx and share the Claquete cookies and transport.
+func (c *Claquete) collect(ctx context.Context) *colly.Collector {
+	collector := colly.NewCollector(
+		colly.UserAgent(c.userAgent),
 	)
+	if c.timeout > 0 {
+		collector.SetRequestTimeout(c.timeout)
+	}
+	collector.SetCookieJar(c.jar)
+	collector.WithTransport(&contextTransport{ctx: ctx, base: c.transport})
+	return collector
+}
+
+// collectState is like collect but uses cookies with the federative unit
+// fu selected, so concurrent operations on different federative units
+// don't see each other's selection. The cookies of each federative unit
+// are kept, selecting it only once. The shared cookies are used when fu
+// is empty.
+func (c *Claquete) collectState(ctx context.Context, fu string) (*colly.Collector, error) {
+	if fu == "" {
+		return c.collect(ctx), nil
+	}
+
+	c.stateMu.Lock()
+	if c.stateJars == nil {
+		c.stateJars = make(map[string]*stateJar)
+	}
+	sj, ok := c.stateJars[fu]
+	if !ok {
+		sj = &stateJar{}
+		c.stateJars[fu] = sj
+	}
+	c.stateMu.Unlock()
+
+	sj.once.Do(func() {
+		sj.jar, _ = cookiejar.New(nil)
+		selector := c.collect(ctx)
+		selector.SetCookieJar(sj.jar)
+		sj.err = selector.Post(c.ajax("escolherEstados.php"), map[string]string{"UF": fu})
+		if sj.err != nil {
+			// Select it again next time
+			c.stateMu.Lock()
+			delete(c.stateJars, fu)
+			c.stateMu.Unlock()
+		}
+	})
+	if sj.err != nil {
+		return nil, sj.err
+	}
+
+	collector := c.collect(ctx)
+	collector.SetCookieJar(sj.jar)
+	return collector, nil
+}
+
+// roundTripper returns the configured transport with TLS settings and
+// fixture recording or replaying applied.
+func (c *Claquete) roundTripper() http.RoundTripper {
+	if c.replay != "" {
+		return &httpreplay.Replayer{Dir: c.replay, Key: cacheKey}
+	}
+
+	rt := c.base
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if c.tlsConfig != nil {
+		if t, ok := rt.(*http.Transport); ok {
+			t = t.Clone()
+			t.TLSClientConfig = c.tlsConfig
+			rt = t
+		}
+	}
+	if c.record != "" {
+		rt = &httpreplay.Recorder{Dir: c.record, Base: rt, Key: cacheKey}
+	}
+	return rt
+}
+
+// httpClient returns a client sharing the collectors' transport, used
+// for requests made outside of colly such as image probes.
+func (c *Claquete) httpClient(ctx context.Context) *http.Client {
+	timeout := c.timeout
+	if timeout == 0 {
+		timeout = time.Second * 10
+	}
+	return &http.Client{
+		Transport: &contextTransport{ctx: ctx, base: c.transport},
+		Timeout:   timeout,
+	}
+}
+
+// url returns the absolute URL for path in the configured website.
+func (c *Claquete) url(path string) string {
+	return c.baseURL + path
+}
+
+// ajax returns the absolute URL for the given AJAX endpoint.
+func (c *Claquete) ajax(endpoint string) string {
+	return c.baseURL + "/lib/ajax/ajax." + endpoint
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// Err returns the error of an invalid option given to NewClaquete, which
+// is also returned by every operation of the Claquete.
+func (c *Claquete) Err() error {
+	return c.err
 }
 
 // FederativeUnit sets the federative unit used by the Claquete.
 func FederativeUnit(fu string) func(*Claquete) {
-	if !isFederativeUnitValid(fu) {
-		log.Fatal(fmt.Errorf("federative unit %s is invalid", fu))
-	}
 	return func(c *Claquete) {
+		if !isFederativeUnitValid(fu) {
+			c.err = errors.Wrapf(ErrInvalidArgument, "federative unit %s", fu)
+			return
+		}
 		c.fu = fu
 	}
 }
 
 // CityName sets the city name used by the Claquete.
 func CityName(city string) func(*Claquete) {
-	if city == "" {
-		log.Fatal(errors.New("city name is invalid"))
-	}
 	return func(c *Claquete) {
+		if city == "" {
+			c.err = errors.Wrap(ErrInvalidArgument, "empty city name")
+			return
+		}
 		c.city = city
 	}
 }
 
+// SiteURL sets the website base URL used by the Claquete, which
+// defaults to BaseURL. Useful to point the client at a test server.
+func SiteURL(u string) func(*Claquete) {
+	return func(c *Claquete) {
+		c.baseURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// Transport sets the http.RoundTripper used for every request made by
+// the Claquete, including image probes.
+func Transport(rt http.RoundTripper) func(*Claquete) {
+	return func(c *Claquete) {
+		c.base = rt
+	}
+}
+
+// Timeout sets the timeout of each request made by the Claquete.
+func Timeout(d time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		c.timeout = d
+	}
+}
+
+// TLSConfig sets the TLS configuration used by the Claquete. It is
+// ignored when a custom Transport other than *http.Transport is set.
+func TLSConfig(cfg *tls.Config) func(*Claquete) {
+	return func(c *Claquete) {
+		c.tlsConfig = cfg
+	}
+}
+
+// RecordFixtures makes the Claquete save every response it gets from the
+// website in dir, to be served later by ReplayFixtures.
+func RecordFixtures(dir string) func(*Claquete) {
+	return func(c *Claquete) {
+		c.record = dir
+	}
+}
+
+// ReplayFixtures makes the Claquete answer requests with the responses
+// recorded in dir instead of reaching the website. Requests that weren't
+// recorded fail with httpreplay.ErrNoFixture.
+func ReplayFixtures(dir string) func(*Claquete) {
+	return func(c *Claquete) {
+		c.replay = dir
+	}
+}
+
+// clientOrDefault returns c or a new default Claquete when c is nil,
+// which happens for values built by hand instead of fetched.
+func clientOrDefault(c *Claquete) *Claquete {
+	if c == nil {
+		return NewClaquete()
+	}
+	return c
+}
+
+// resolveURL resolves ref against base, returning ref untouched when
+// either of them can't be parsed.
+func resolveURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
 // GetReleases get releases of week
 func (c *Claquete) GetReleases() ([]Movie, error) {
+	return c.GetReleasesContext(context.Background())
+}
+
+// GetReleasesContext is like GetReleases but aborts when ctx is done.
+func (c *Claquete) GetReleasesContext(ctx context.Context) ([]Movie, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var result []Movie
-	var err error
+	var errs ParseErrors
+
+	collector := c.collect(ctx)
 
-	c.collector.OnHTML("#carrossel > ul:nth-child(1) > li", func(e *colly.HTMLElement) {
+	collector.OnHTML(selReleases, func(e *colly.HTMLElement) {
 		m := Movie{
 			Page:   e.DOM.Find("a").AttrOr("href", ""),
 			Title:  strings.TrimSpace(e.DOM.Find("p").Text()),
@@ -88,7 +341,7 @@ func (c *Claquete) GetReleases() ([]Movie, error) {
 
 		ID, err := movieutil.IDFromURLString(m.Page)
 		if err != nil {
-			err = errors.Wrapf(err, "get url from %s failed", m.Page)
+			errs.add(e.Request.URL.String(), selReleases+" a", m.Page, err)
 			return
 		}
 
@@ -98,7 +351,14 @@ func (c *Claquete) GetReleases() ([]Movie, error) {
 		result = append(result, m)
 	})
 
-	err = c.collector.Visit(BaseURL + "/noticias.html")
+	err := collector.Visit(c.url("/noticias.html"))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return result, err
+	c.report(errs)
+	return result, nil
 }
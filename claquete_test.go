@@ -1,13 +1,174 @@
 package claquete
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestGetReleases(t *testing.T) {
-	c := NewClaquete()
+	c := NewClaquete(fixtures(t))
 	_, err := c.GetReleases()
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
 }
+
+func TestContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetMovieContext(ctx, 8427); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, but got: %v", err)
+	}
+
+	if _, err := GetScheduleContext(ctx, 656); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, but got: %v", err)
+	}
+
+	if _, err := SearchContext(ctx, "cinemais"); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, but got: %v", err)
+	}
+
+	if _, err := SearchMoviesContext(ctx, "vingadores"); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, but got: %v", err)
+	}
+}
+
+type countingTransport struct {
+	n int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.n, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newReleasesServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/noticias.html" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><div id="carrossel"><ul><li>
+<a href="http://claquete.com.br/filmes/filme.php?cf=8427"><img src="/poster.jpg"></a>
+<p> O Retorno de Mary Poppins </p>
+</li></ul></div></body></html>`)
+	}))
+}
+
+func TestSiteURL(t *testing.T) {
+	ts := newReleasesServer()
+	defer ts.Close()
+
+	rt := &countingTransport{}
+	c := NewClaquete(SiteURL(ts.URL), Transport(rt), Timeout(5*time.Second))
+	releases, err := c.GetReleases()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release, got %d", len(releases))
+	}
+
+	if releases[0].ID != 8427 || releases[0].Title != "O Retorno de Mary Poppins" {
+		t.Fatalf("unexpected release %+v", releases[0])
+	}
+
+	if atomic.LoadInt32(&rt.n) != 1 {
+		t.Fatalf("expected 1 request through transport, got %d", rt.n)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	ts := newReleasesServer()
+	defer ts.Close()
+
+	c := NewClaquete(SiteURL(ts.URL))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				releases, err := c.GetReleases()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(releases) != 1 {
+					errs <- fmt.Errorf("expected 1 release, got %d", len(releases))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestStateCookies(t *testing.T) {
+	var selected int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/lib/ajax/ajax.escolherEstados.php":
+			atomic.AddInt32(&selected, 1)
+			http.SetCookie(w, &http.Cookie{Name: "uf", Value: r.Form.Get("UF"), Path: "/"})
+		case "/lib/ajax/ajax.escolherCinema_load.php":
+			if cookie, err := r.Cookie("uf"); err == nil && cookie.Value == MG {
+				fmt.Fprint(w, `<option value="656">Cinemais Montes Claros</option>`)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClaquete(SiteURL(ts.URL), FederativeUnit(MG), CityName("Montes Claros"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cinemas, err := c.GetCinemas()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(cinemas) != 1 {
+				errs <- fmt.Errorf("expected 1 cinema, got %d", len(cinemas))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&selected) != 1 {
+		t.Fatalf("expected the state to be selected once, got %d", selected)
+	}
+
+	if cinemas, err := c.getCinemas(context.Background(), PB, "Montes Claros"); err != nil || len(cinemas) != 0 {
+		t.Fatalf("expected no cinemas in PB, got %+v (%v)", cinemas, err)
+	}
+	if atomic.LoadInt32(&selected) != 2 {
+		t.Fatalf("expected 2 selected states, got %d", selected)
+	}
+}
@@ -0,0 +1,530 @@
+package claquetetest
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+	"github.com/dsbezerra/claqueteapi/util"
+)
+
+type (
+	option struct {
+		Value int
+		Text  string
+	}
+
+	state struct {
+		FU   string
+		Name string
+	}
+
+	indexPage struct {
+		States []state
+		Cities []string
+	}
+
+	movieLink struct {
+		Title  string
+		Poster string
+		Page   string
+	}
+
+	releaseWeek struct {
+		Day    int
+		Movies []movieLink
+	}
+
+	field struct {
+		Label string
+		Value string
+	}
+
+	moviePage struct {
+		Poster   string
+		Rating   string
+		Title    string
+		Original string
+		Fields   []field
+		Sections []field
+		Images   []string
+	}
+
+	searchResult struct {
+		Title string
+		Page  string
+		Info  string
+	}
+
+	searchPage struct {
+		Count   int
+		Cinemas []searchResult
+		Movies  []searchResult
+		News    []searchResult
+	}
+
+	headline struct {
+		Title    string
+		Category string
+		Date     string
+		Image    string
+		Page     string
+	}
+
+	headlinesPage struct {
+		Releases  []movieLink
+		Headlines []headline
+	}
+
+	newsPage struct {
+		Title    string
+		Category string
+		Date     string
+		Author   string
+		Content  string
+	}
+
+	note struct {
+		Letter string
+		Hint   string
+	}
+
+	scheduleMovie struct {
+		Page  string
+		Title string
+		Hints []string
+		Room  string
+		Times []string
+	}
+
+	schedulePage struct {
+		ID      int
+		State   string
+		Name    string
+		Address string
+		Map     string
+		Start   string
+		End     string
+		Notes   []note
+		Movies  []scheduleMovie
+	}
+)
+
+var states = []state{
+	{claquete.AC, claquete.Acre}, {claquete.AL, claquete.Alagoas},
+	{claquete.AM, claquete.Amazonas}, {claquete.AP, claquete.Amapa},
+	{claquete.BA, claquete.Bahia}, {claquete.CE, claquete.Ceara},
+	{claquete.DF, claquete.DistritoFederal}, {claquete.ES, claquete.EspiritoSanto},
+	{claquete.GO, claquete.Goias}, {claquete.MA, claquete.Maranhao},
+	{claquete.MG, claquete.MinasGerais}, {claquete.MS, claquete.MatoGrossoSul},
+	{claquete.MT, claquete.MatoGrosso}, {claquete.PA, claquete.Para},
+	{claquete.PB, claquete.Paraiba}, {claquete.PE, claquete.Pernambuco},
+	{claquete.PI, claquete.Piaui}, {claquete.PR, claquete.Parana},
+	{claquete.RJ, claquete.RioJaneiro}, {claquete.RN, claquete.RioGrandeNorte},
+	{claquete.RO, claquete.Rondonia}, {claquete.RR, claquete.Roraima},
+	{claquete.RS, claquete.RioGrandeSul}, {claquete.SC, claquete.SantaCatarina},
+	{claquete.SE, claquete.Sergipe}, {claquete.SP, claquete.SaoPaulo},
+	{claquete.TO, claquete.Tocantins},
+}
+
+var weekdays = []string{"Dom.", "Seg.", "Ter.", "Qua.", "Qui.", "Sex.", "Sáb."}
+
+var pages = template.Must(template.New("").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html lang="pt-br">
+<head>
+<meta charset="utf-8">
+<title>{{.}} - Claquete</title>
+</head>
+<body>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "empty"}}{{template "header" "Claquete"}}<div class="conteudo"></div>
+{{template "footer"}}{{end}}
+
+{{define "index"}}{{template "header" "Claquete"}}<select id="selUf">
+	<option value="0">UF</option>{{range .States}}
+	<option value="{{.FU}}">{{.Name}}</option>{{end}}
+</select>
+<select id="cidade">
+	<option value="0">Cidade</option>{{range .Cities}}
+	<option value="{{.}}">{{.}}</option>{{end}}
+</select>
+{{template "footer"}}{{end}}
+
+{{define "options"}}<option value="">Selecione</option>{{range .}}
+<option value="{{.Value}}">{{.Text}}</option>{{end}}
+{{end}}
+
+{{define "calendar"}}{{range .}}<div class="cxsem"><p>{{.Day}}</p></div>
+<ul class="posters">{{range .Movies}}
+	<li>
+		<a href="{{.Page}}"><img src="{{.Poster}}"><div data-hint="Sinopse"></div></a>
+		<ul><li><p>{{.Title}}</p></li></ul>
+	</li>{{end}}
+</ul>
+{{end}}{{end}}
+
+{{define "schedule"}}{{template "header" .Name}}<div class="conteudo">
+<div class="progrb">
+	<div>
+		<p>por cinemas em {{.State}}</p>
+	</div>
+	<div class="cinema{{.ID}}">
+		<div>
+			<div>
+				<div class="ttcine"><h2>{{.Name}}</h2></div>
+				<span>{{.Address}}{{if .Map}}
+				<a href="{{.Map}}">(mapa)</a>{{end}}</span>
+			</div>
+		</div>
+		<h4>Programação de <strong>{{.Start}}</strong> a <strong>{{.End}}</strong></h4>
+		<div class="notas">{{range .Notes}}
+			<div data-hint="{{.Hint}}"><span class="hleter">{{.Letter}}</span></div>{{end}}
+		</div>{{range .Movies}}
+		<div class="filme">
+			<h2><a href="{{.Page}}">{{.Title}}</a></h2>
+			<div class="icons">{{range .Hints}}
+				<div data-hint="{{.}}"></div>{{end}}
+			</div>
+			<h2 class="salas">{{.Room}} {{range $i, $t := .Times}}{{if $i}}, {{end}}{{$t}}{{end}}</h2>
+		</div>{{end}}
+	</div>
+</div>
+</div>
+{{template "footer"}}{{end}}
+
+{{define "movie"}}{{template "header" .Title}}<div class="conteudo">
+	<div class="mvposter">
+		<img src="{{.Poster}}">{{if .Rating}}
+		<div class="mvclassif"><img src="{{.Rating}}"></div>{{end}}
+	</div>
+	<div class="mvdesc">
+		<h1>{{.Title}}</h1>{{if .Original}}
+		<h2>{{.Original}}</h2>{{end}}{{range .Fields}}
+		<p>{{.Label}}: {{.Value}}</p>{{end}}
+	</div>
+	<div id="cont1">{{range .Sections}}
+		<h3>{{.Label}}</h3>
+		<p>{{.Value}}</p>{{end}}
+	</div>
+	<div id="cont2">{{range .Images}}
+		<img src="{{.}}">{{end}}
+	</div>
+</div>
+{{template "footer"}}{{end}}
+
+{{define "search"}}<div id="busca_ajax">
+	<h3>{{.Count}} resultados encontrados</h3>{{if .Cinemas}}
+	<div class="ttsubn">Cinema</div>{{range .Cinemas}}
+	<span></span>
+	<h2><a href="{{.Page}}">{{.Title}}</a></h2>{{end}}{{end}}{{if .Movies}}
+	<div class="ttsubn">Filme</div>{{range .Movies}}
+	<span>{{.Info}}</span>
+	<h2><a href="{{.Page}}">{{.Title}}</a></h2>{{end}}{{end}}{{if .News}}
+	<div class="ttsubn">Notícias</div>{{range .News}}
+	<span>{{.Info}}</span>
+	<h2><a href="{{.Page}}">{{.Title}}</a></h2>{{end}}{{end}}
+</div>
+{{end}}
+
+{{define "headlines"}}{{template "header" "Notícias"}}<div id="carrossel">
+<ul>{{range .Releases}}
+	<li><a href="{{.Page}}"><img src="{{.Poster}}"></a><p>{{.Title}}</p></li>{{end}}
+</ul>
+</div>
+<div class="conteudo">
+<div class="noticias">{{range $i, $h := .Headlines}}{{if eq $i 0}}
+	<a href="{{.Page}}"><div class="principal"><img src="{{.Image}}"><div class="ttprincipal">{{.Category}}</div></div></a>
+	<span>{{.Date}}</span>
+	<h1><a href="{{.Page}}">{{.Title}}</a></h1>{{else}}
+	<div><div class="subn">{{.Category}}</div></div>
+	<span>{{.Date}}</span>
+	<h2><a href="{{.Page}}">{{.Title}}</a></h2>{{end}}{{end}}
+</div>
+</div>
+{{template "footer"}}{{end}}
+
+{{define "news"}}{{template "header" .Title}}<div class="conteudo">
+<div class="noticias">
+	<span>{{.Date}}</span>
+	<div class="subn">{{.Category}}</div>
+	<h1>{{.Title}}</h1>
+	<span>{{.Author}}</span>
+	<p>{{.Content}}</p>
+</div>
+</div>
+{{template "footer"}}{{end}}
+`))
+
+func render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// formatDate formats t as in "20 de maio de 2019".
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d de %s de %d", t.Day(), claquete.Months[t.Month()-1], t.Year())
+}
+
+func (s *Server) movieLink(m claquete.Movie) movieLink {
+	name := m.Slug
+	if name == "" {
+		name = util.CreateSlug(m.Title)
+	}
+	return movieLink{
+		Title:  m.Title,
+		Poster: m.Poster,
+		Page:   s.URL + "/" + strconv.Itoa(m.ID) + "/" + name + ".html",
+	}
+}
+
+func (s *Server) moviePage(m claquete.Movie) moviePage {
+	page := moviePage{
+		Poster: m.Poster,
+		Title:  m.Title,
+	}
+
+	switch m.Rating {
+	case -1:
+		page.Rating = s.URL + "/" + claquete.RatingL
+	case 10:
+		page.Rating = s.URL + "/" + claquete.Rating10
+	case 12:
+		page.Rating = s.URL + "/" + claquete.Rating12
+	case 14:
+		page.Rating = s.URL + "/" + claquete.Rating14
+	case 16:
+		page.Rating = s.URL + "/" + claquete.Rating16
+	case 18:
+		page.Rating = s.URL + "/" + claquete.Rating18
+	}
+
+	var year string
+	if m.ReleaseDate != nil {
+		year = strconv.Itoa(m.ReleaseDate.Year())
+	}
+	if m.OriginalTitle != "" {
+		page.Original = "(" + m.OriginalTitle + ", " + year + ")"
+	} else if year != "" {
+		page.Original = "(Ainda Sem Título em Português, " + year + ")"
+	}
+
+	add := func(fields *[]field, label, value string) {
+		if value != "" {
+			*fields = append(*fields, field{label, value})
+		}
+	}
+	add(&page.Fields, "País", m.Country)
+	add(&page.Fields, "Gênero", strings.Join(m.Genres, ", "))
+	if m.Runtime > 0 {
+		add(&page.Fields, "Duração", strconv.Itoa(m.Runtime)+" min")
+	}
+	add(&page.Fields, "Distr.", m.Distributor)
+	if m.ReleaseDate != nil {
+		add(&page.Fields, "Estreia", m.ReleaseDate.Format("02/01/2006"))
+	}
+
+	add(&page.Sections, "Sinopse", m.Synopsis)
+	add(&page.Sections, "Elenco", strings.Join(m.Cast, ", "))
+	add(&page.Sections, "Roteiro", strings.Join(m.Screenplay, ", "))
+	add(&page.Sections, "Produção", strings.Join(m.Production, ", "))
+	add(&page.Sections, "Direção", strings.Join(m.Direction, ", "))
+
+	for _, i := range m.Images {
+		page.Images = append(page.Images, i.URL)
+	}
+
+	return page
+}
+
+// sessionGroup is a movie in a room with the same version and features,
+// which the website lists in a single block.
+type sessionGroup struct {
+	session claquete.Session
+	times   map[int][]time.Time
+}
+
+func (s *Server) schedulePage(c *cinema) schedulePage {
+	sched := c.schedule
+	page := schedulePage{
+		ID:      c.ID,
+		State:   c.city.State.Name,
+		Name:    c.Name,
+		Address: c.AddressLine,
+	}
+	if c.Coordinates != nil {
+		page.Map = fmt.Sprintf("https://maps.google.com/maps?q=%g,%g", c.Coordinates.Latitude, c.Coordinates.Longitude)
+	}
+	if page.State == "" {
+		page.State = claquete.SaoPaulo
+	}
+
+	period := sched.Period
+	if period == nil {
+		period = &claquete.Period{}
+		for i, session := range sched.Sessions {
+			if session.StartTime == nil {
+				continue
+			}
+			y, m, d := session.StartTime.Date()
+			day := time.Date(y, m, d, 0, 0, 0, 0, session.StartTime.Location())
+			if i == 0 || day.Before(period.Start) {
+				period.Start = day
+			}
+		}
+		period.End = period.Start.AddDate(0, 0, 6)
+	}
+	page.Start = period.Start.Format("02/01/2006")
+	page.End = period.End.Format("02/01/2006")
+
+	var days []time.Time
+	for d := period.Start; !d.After(period.End); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	var groups []*sessionGroup
+	for _, session := range sched.Sessions {
+		if session.StartTime == nil {
+			continue
+		}
+		key := session
+		key.StartTime, key.EndTime, key.Notes = nil, nil, ""
+		var g *sessionGroup
+		for _, gg := range groups {
+			if reflect.DeepEqual(gg.session, key) {
+				g = gg
+				break
+			}
+		}
+		if g == nil {
+			g = &sessionGroup{session: key, times: make(map[int][]time.Time)}
+			groups = append(groups, g)
+		}
+		t := *session.StartTime
+		clock := t.Hour()*60 + t.Minute()
+		g.times[clock] = append(g.times[clock], t)
+	}
+
+	letters := make(map[string]string)
+	letterOf := func(hint string) string {
+		letter, ok := letters[hint]
+		if !ok {
+			letter = string(rune('A' + len(letters)))
+			letters[hint] = letter
+			page.Notes = append(page.Notes, note{letter, hint})
+		}
+		return letter
+	}
+	for _, g := range groups {
+		movie := scheduleMovie{
+			Page:  s.URL + "/filmes/filme.php?cf=" + strconv.Itoa(g.session.MovieID),
+			Title: g.session.MovieTitle,
+			Hints: hints(g.session),
+			Room:  g.session.Room.String(),
+		}
+
+		var clocks []int
+		for clock := range g.times {
+			clocks = append(clocks, clock)
+		}
+		sort.Ints(clocks)
+
+		for _, clock := range clocks {
+			t := fmt.Sprintf("%02dh%02d", clock/60, clock%60)
+			if hint := noteHint(days, g.times[clock]); hint != "" {
+				t += letterOf(hint)
+			}
+			if g.session.PreSale {
+				t += letterOf("Pré-venda")
+			}
+			if g.session.Premiere {
+				t += letterOf("Pré-estreia")
+			}
+			movie.Times = append(movie.Times, t)
+		}
+		page.Movies = append(page.Movies, movie)
+	}
+
+	return page
+}
+
+// hints returns the icon hints describing the session.
+func hints(session claquete.Session) []string {
+	var result []string
+	switch session.Version {
+	case claquete.VersionDubbed:
+		result = append(result, "Dublado")
+	case claquete.VersionSubtitled:
+		result = append(result, "Legendado")
+	case claquete.VersionNational:
+		result = append(result, "Nacional")
+	}
+	switch session.Format {
+	case claquete.Format3D, claquete.Format4DX:
+		result = append(result, session.Format)
+	}
+	for _, f := range session.Features {
+		if f == claquete.FeatureVIP {
+			result = append(result, "Sala VIP")
+			continue
+		}
+		result = append(result, f.String())
+	}
+	return result
+}
+
+// noteHint returns the note for a time played on the given dates, or an
+// empty string when it is played every day of the period.
+func noteHint(days []time.Time, dates []time.Time) string {
+	played := make(map[string]bool)
+	for _, d := range dates {
+		played[d.Format("02/01")] = true
+	}
+
+	var except []time.Time
+	inPeriod := 0
+	for _, d := range days {
+		if played[d.Format("02/01")] {
+			inPeriod++
+		} else {
+			except = append(except, d)
+		}
+	}
+
+	if len(except) == 0 && inPeriod == len(dates) {
+		return ""
+	}
+	if inPeriod == len(dates) && len(except) <= inPeriod {
+		return "Exceto " + formatDays(except)
+	}
+	return "Somente " + formatDays(dates)
+}
+
+// formatDays formats days as in "Sáb. (18/05) Dom. (19/05)".
+func formatDays(days []time.Time) string {
+	sorted := make([]time.Time, len(days))
+	copy(sorted, days)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	var result []string
+	for _, d := range sorted {
+		result = append(result, weekdays[d.Weekday()]+" ("+d.Format("02/01")+")")
+	}
+	return strings.Join(result, " ")
+}
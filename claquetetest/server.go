@@ -0,0 +1,421 @@
+// Package claquetetest provides a fake Claquete website for testing code
+// that depends on the claquete package.
+//
+// The fake site is programmed with the same structs the claquete package
+// returns and generates pages the real parsers consume:
+//
+//	s := claquetetest.NewServer()
+//	defer s.Close()
+//
+//	s.AddCinema(city, claquete.Cinema{ID: 656, Name: "Cinemais Montes Claros"})
+//	s.AddSchedule(claquete.Schedule{Cinema: &claquete.Cinema{ID: 656}, Sessions: sessions})
+//
+//	sched, err := claquete.GetSchedule(656, s.Options())
+package claquetetest
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+	"github.com/dsbezerra/claqueteapi/util"
+)
+
+type (
+	// Server is a fake Claquete website serving the data added to it.
+	//
+	// It is safe to add data while the server is handling requests.
+	Server struct {
+		*httptest.Server
+
+		mu       sync.RWMutex
+		cities   []claquete.City
+		cinemas  []*cinema
+		movies   map[int]claquete.Movie
+		releases []claquete.Movie
+		news     []*news
+	}
+
+	cinema struct {
+		claquete.Cinema
+		city     claquete.City
+		schedule *claquete.Schedule
+	}
+
+	news struct {
+		claquete.News
+		id int
+	}
+)
+
+var (
+	reCinemaPage = regexp.MustCompile(`^/programacao/(\d+)/`)
+	reNewsPage   = regexp.MustCompile(`^/noticia/(\d+)/`)
+	reMoviePage  = regexp.MustCompile(`^/(\d+)/`)
+)
+
+// NewServer starts a fake Claquete website. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		movies: make(map[int]claquete.Movie),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Options returns the option pointing a Claquete at the server.
+func (s *Server) Options() claquete.Options {
+	return claquete.SiteURL(s.URL)
+}
+
+// AddCity adds a city to the list of its state.
+func (s *Server) AddCity(city claquete.City) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addCity(city)
+}
+
+func (s *Server) addCity(city claquete.City) {
+	for _, c := range s.cities {
+		if c.Name == city.Name && c.State.FU == city.State.FU {
+			return
+		}
+	}
+	s.cities = append(s.cities, city)
+}
+
+// AddCinema adds a cinema to the given city, adding the city if needed.
+func (s *Server) AddCinema(city claquete.City, c claquete.Cinema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addCity(city)
+	e := s.cinema(c.ID)
+	e.Cinema = c
+	e.city = city
+}
+
+// cinema returns the entry for the cinema id, creating it if needed.
+func (s *Server) cinema(id int) *cinema {
+	for _, c := range s.cinemas {
+		if c.ID == id {
+			return c
+		}
+	}
+	c := &cinema{Cinema: claquete.Cinema{ID: id}}
+	s.cinemas = append(s.cinemas, c)
+	return c
+}
+
+// AddSchedule sets the schedule of sched.Cinema, which is added if it
+// wasn't yet. When sched.Period is nil it is the week starting at the
+// first session.
+//
+// Session start times are rendered in their own location, which should
+// be the cinema time zone.
+func (s *Server) AddSchedule(sched claquete.Schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.cinema(sched.Cinema.ID)
+	if e.Name == "" {
+		e.Cinema = *sched.Cinema
+	}
+	e.schedule = &sched
+}
+
+// AddMovie adds a movie page.
+func (s *Server) AddMovie(m claquete.Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.movies[m.ID] = m
+}
+
+// AddRelease adds a movie to the releases carousel and, when it has a
+// ReleaseDate, to the release calendar of that month.
+func (s *Server) AddRelease(m claquete.Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = append(s.releases, m)
+}
+
+// AddNews adds a news page with the given ID and its headline to the news
+// listing.
+func (s *Server) AddNews(id int, n claquete.News) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.news = append(s.news, &news{News: n, id: id})
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r.ParseForm()
+	path := r.URL.Path
+
+	switch {
+	case path == "/" || path == "":
+		s.serveIndex(w, r)
+	case path == "/filmes/filme.php":
+		id, _ := strconv.Atoi(r.Form.Get("cf"))
+		s.serveMovie(w, id)
+	case path == "/busca.html":
+		s.serveSearch(w, r.Form.Get("query"))
+	case path == "/noticias.html":
+		s.serveHeadlines(w)
+	case strings.HasPrefix(path, "/lib/ajax/ajax."):
+		s.serveAJAX(w, r, strings.TrimPrefix(path, "/lib/ajax/ajax."))
+	case strings.HasPrefix(path, "/fotos/"), strings.HasPrefix(path, "/img/"):
+		serveImage(w, path)
+	case reCinemaPage.MatchString(path):
+		id, _ := strconv.Atoi(reCinemaPage.FindStringSubmatch(path)[1])
+		s.serveSchedule(w, id)
+	case reNewsPage.MatchString(path):
+		id, _ := strconv.Atoi(reNewsPage.FindStringSubmatch(path)[1])
+		s.serveNews(w, id)
+	case reMoviePage.MatchString(path):
+		id, _ := strconv.Atoi(reMoviePage.FindStringSubmatch(path)[1])
+		s.serveMovie(w, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	page := indexPage{States: states}
+	if cookie, err := r.Cookie("uf"); err == nil {
+		for _, c := range s.cities {
+			if c.State.FU == cookie.Value {
+				page.Cities = append(page.Cities, c.Name)
+			}
+		}
+	}
+	render(w, "index", page)
+}
+
+func (s *Server) serveAJAX(w http.ResponseWriter, r *http.Request, endpoint string) {
+	switch endpoint {
+	case "escolherEstados.php":
+		http.SetCookie(w, &http.Cookie{Name: "uf", Value: r.Form.Get("UF"), Path: "/"})
+	case "escolherCinema_load.php":
+		var options []option
+		for _, c := range s.cinemas {
+			if inCity(c, r) {
+				options = append(options, option{c.ID, c.Name})
+			}
+		}
+		render(w, "options", options)
+	case "escolherFilme.php":
+		id, _ := strconv.Atoi(r.Form.Get("cinema"))
+		render(w, "options", s.nowPlaying(func(c *cinema) bool { return c.ID == id }))
+	case "escolherFilme_cidade.php":
+		render(w, "options", s.nowPlaying(func(c *cinema) bool { return inCity(c, r) }))
+	case "calendario.php":
+		year, _ := strconv.Atoi(r.Form.Get("ano"))
+		month, _ := strconv.Atoi(r.Form.Get("mes"))
+		render(w, "calendar", s.calendar(time.Month(month), year))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// inCity reports whether c is in the city of the request, in the state
+// selected in escolherEstados.php.
+func inCity(c *cinema, r *http.Request) bool {
+	var uf string
+	if cookie, err := r.Cookie("uf"); err == nil {
+		uf = cookie.Value
+	}
+	return c.city.Name == r.Form.Get("cidade") && c.city.State.FU == uf
+}
+
+// nowPlaying lists the movies in the schedules of the matching cinemas.
+func (s *Server) nowPlaying(match func(*cinema) bool) []option {
+	var result []option
+	seen := make(map[int]bool)
+	for _, c := range s.cinemas {
+		if c.schedule == nil || !match(c) {
+			continue
+		}
+		for _, session := range c.schedule.Sessions {
+			if !seen[session.MovieID] {
+				seen[session.MovieID] = true
+				result = append(result, option{session.MovieID, session.MovieTitle})
+			}
+		}
+	}
+	return result
+}
+
+func (s *Server) calendar(month time.Month, year int) []releaseWeek {
+	var result []releaseWeek
+	for _, m := range s.releases {
+		if m.ReleaseDate == nil {
+			continue
+		}
+		y, mm, d := m.ReleaseDate.Date()
+		if y != year || mm != month {
+			continue
+		}
+		i := sort.Search(len(result), func(i int) bool { return result[i].Day >= d })
+		if i == len(result) || result[i].Day != d {
+			result = append(result, releaseWeek{})
+			copy(result[i+1:], result[i:])
+			result[i] = releaseWeek{Day: d}
+		}
+		result[i].Movies = append(result[i].Movies, s.movieLink(m))
+	}
+	return result
+}
+
+func (s *Server) serveSchedule(w http.ResponseWriter, id int) {
+	for _, c := range s.cinemas {
+		if c.ID == id && c.schedule != nil {
+			render(w, "schedule", s.schedulePage(c))
+			return
+		}
+	}
+	render(w, "empty", nil)
+}
+
+func (s *Server) serveMovie(w http.ResponseWriter, id int) {
+	m, ok := s.movies[id]
+	if !ok {
+		render(w, "empty", nil)
+		return
+	}
+	render(w, "movie", s.moviePage(m))
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, query string) {
+	query = strings.ToLower(query)
+	match := func(str string) bool {
+		return query != "" && strings.Contains(strings.ToLower(str), query)
+	}
+
+	var page searchPage
+	for _, c := range s.cinemas {
+		if match(c.Name) {
+			page.Cinemas = append(page.Cinemas, searchResult{
+				Title: c.Name,
+				Page:  s.URL + "/programacao/" + strconv.Itoa(c.ID) + "/" + util.CreateSlug(c.Name) + ".html",
+			})
+		}
+	}
+	for _, m := range s.sortedMovies() {
+		if match(m.Title) {
+			r := searchResult{Title: m.Title, Page: s.movieLink(m).Page}
+			if m.ReleaseDate != nil {
+				r.Info = strconv.Itoa(m.ReleaseDate.Year())
+			}
+			page.Movies = append(page.Movies, r)
+		}
+	}
+	for _, n := range s.news {
+		if match(n.Headline.Title) {
+			page.News = append(page.News, searchResult{
+				Title: n.Headline.Title,
+				Page:  s.newsURL(n),
+				Info:  formatDate(n.Headline.Date),
+			})
+		}
+	}
+	page.Count = len(page.Cinemas) + len(page.Movies) + len(page.News)
+	render(w, "search", page)
+}
+
+func (s *Server) serveHeadlines(w http.ResponseWriter) {
+	var page headlinesPage
+	for _, m := range s.releases {
+		page.Releases = append(page.Releases, s.movieLink(m))
+	}
+
+	sorted := make([]*news, len(s.news))
+	copy(sorted, s.news)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dateOf(sorted[i]).After(dateOf(sorted[j]))
+	})
+	for _, n := range sorted {
+		page.Headlines = append(page.Headlines, headline{
+			Title:    n.Headline.Title,
+			Category: n.Headline.Category,
+			Date:     formatDate(n.Headline.Date),
+			Image:    n.Headline.Image,
+			Page:     s.newsURL(n),
+		})
+	}
+	render(w, "headlines", page)
+}
+
+func (s *Server) serveNews(w http.ResponseWriter, id int) {
+	for _, n := range s.news {
+		if n.id == id {
+			render(w, "news", newsPage{
+				Title:    n.Headline.Title,
+				Category: n.Headline.Category,
+				Date:     formatDate(n.Headline.Date),
+				Author:   n.Author,
+				Content:  n.Content,
+			})
+			return
+		}
+	}
+	render(w, "empty", nil)
+}
+
+func (s *Server) sortedMovies() []claquete.Movie {
+	result := make([]claquete.Movie, 0, len(s.movies))
+	for _, m := range s.movies {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (s *Server) newsURL(n *news) string {
+	return s.URL + "/noticia/" + strconv.Itoa(n.id) + "/" + util.CreateSlug(n.Headline.Title) + ".html"
+}
+
+func dateOf(n *news) time.Time {
+	if n.Headline.Date == nil {
+		return time.Time{}
+	}
+	return *n.Headline.Date
+}
+
+var (
+	imageMu    sync.Mutex
+	imageCache = make(map[string][]byte)
+)
+
+// serveImage answers with a blank PNG, in portrait orientation for
+// posters so they are classified as such.
+func serveImage(w http.ResponseWriter, path string) {
+	width, height := 300, 200
+	if strings.Contains(path, "poster") {
+		width, height = 200, 300
+	}
+	key := strconv.Itoa(width) + "x" + strconv.Itoa(height)
+
+	imageMu.Lock()
+	data, ok := imageCache[key]
+	if !ok {
+		var buf bytes.Buffer
+		png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)))
+		data = buf.Bytes()
+		imageCache[key] = data
+	}
+	imageMu.Unlock()
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
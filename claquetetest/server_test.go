@@ -0,0 +1,253 @@
+package claquetetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+)
+
+var montesClaros = claquete.City{
+	Name:  "Montes Claros",
+	State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais},
+}
+
+func TestSchedule(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	// Notes carry no year, keep dates in the current one
+	start := time.Date(time.Now().In(loc).Year(), time.March, 1, 0, 0, 0, 0, loc)
+	at := func(day, hours, minutes int) *time.Time {
+		t := start.AddDate(0, 0, day).Add(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute)
+		return &t
+	}
+
+	mary := claquete.Session{
+		CinemaID:   656,
+		MovieID:    8427,
+		MovieTitle: "O Retorno de Mary Poppins",
+		Format:     claquete.Format3D,
+		Version:    claquete.VersionDubbed,
+		Room:       claquete.Room{Label: "Sala 1", Number: 1},
+	}
+	avengers := claquete.Session{
+		CinemaID:   656,
+		MovieID:    8600,
+		MovieTitle: "Vingadores: Ultimato",
+		Format:     claquete.Format2D,
+		Version:    claquete.VersionSubtitled,
+		Room:       claquete.Room{Label: "Sala 2", Number: 2},
+		Features:   claquete.Features{claquete.FeatureVIP},
+	}
+
+	var sessions []claquete.Session
+	add := func(session claquete.Session, days []int, hours, minutes int) {
+		for _, d := range days {
+			session.StartTime = at(d, hours, minutes)
+			sessions = append(sessions, session)
+		}
+	}
+	add(mary, []int{0, 1, 2, 3, 4, 5, 6}, 14, 0)
+	add(mary, []int{2, 3}, 17, 30)
+	add(mary, []int{0, 1, 2, 3, 4, 5}, 21, 0)
+	add(avengers, []int{0, 1, 2, 3, 4, 5, 6}, 20, 15)
+
+	cinema := claquete.Cinema{
+		ID:          656,
+		Name:        "Cinemais Montes Claros",
+		AddressLine: "Av. Donato Quintino, 90 - Cidade Nova, Montes Claros - MG",
+		Coordinates: &claquete.Coordinates{Latitude: -16.7211, Longitude: -43.8647},
+	}
+	s.AddCinema(montesClaros, cinema)
+	s.AddSchedule(claquete.Schedule{Cinema: &cinema, Sessions: sessions})
+
+	sched, err := claquete.GetSchedule(656, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(sched.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", sched.Diagnostics)
+	}
+	if sched.Cinema.Name != cinema.Name || sched.Cinema.AddressLine != cinema.AddressLine {
+		t.Fatalf("expected %+v, got %+v", cinema, sched.Cinema)
+	}
+	if !sched.Period.Start.Equal(start) {
+		t.Fatalf("expected %s, got %s", start, sched.Period.Start)
+	}
+
+	if len(sched.Sessions) != len(sessions) {
+		t.Fatalf("expected %d sessions, got %d", len(sessions), len(sched.Sessions))
+	}
+	for i, expected := range sessions {
+		actual := sched.Sessions[i]
+		if !actual.StartTime.Equal(*expected.StartTime) {
+			t.Fatalf("expected session at %s, got %s", expected.StartTime, actual.StartTime)
+		}
+		actual.StartTime, expected.StartTime = nil, nil
+		actual.Notes = ""
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %+v, got %+v", expected, actual)
+		}
+	}
+
+	got, err := claquete.GetCinema(656, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if got.Name != cinema.Name || got.TimeZone != "America/Sao_Paulo" || got.Address.City != "Montes Claros" {
+		t.Fatalf("unexpected cinema %+v", got)
+	}
+	if got.Coordinates == nil || *got.Coordinates != *cinema.Coordinates {
+		t.Fatalf("unexpected cinema %+v", got)
+	}
+
+	movies, err := got.GetNowPlaying()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(movies) != 2 || movies[0].ID != 8427 || movies[1].ID != 8600 {
+		t.Fatalf("unexpected movies %+v", movies)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddCinema(montesClaros, claquete.Cinema{ID: 656, Name: "Cinemais Montes Claros"})
+	s.AddCinema(montesClaros, claquete.Cinema{ID: 657, Name: "Moviecom Montes Claros"})
+
+	states, err := claquete.GetStates(s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(states) != len(claquete.FederativeUnits) {
+		t.Fatalf("expected %d states, got %d", len(claquete.FederativeUnits), len(states))
+	}
+
+	cities, err := claquete.GetCities(claquete.MG, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cities) != 1 || cities[0].Name != montesClaros.Name {
+		t.Fatalf("unexpected cities %+v", cities)
+	}
+
+	cinemas, err := claquete.GetCinemas(claquete.MG, montesClaros.Name, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cinemas) != 2 || cinemas[1].ID != 657 {
+		t.Fatalf("unexpected cinemas %+v", cinemas)
+	}
+}
+
+func TestMovie(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	release := time.Date(2018, time.December, 20, 0, 0, 0, 0, loc)
+	s.AddMovie(claquete.Movie{
+		ID:            8427,
+		Title:         "O Retorno de Mary Poppins",
+		OriginalTitle: "Mary Poppins Returns",
+		Synopsis:      "Michael Banks perde a esposa.",
+		Country:       "EUA",
+		Distributor:   "Walt Disney Studios",
+		Genres:        []string{"Família", "Fantasia"},
+		Cast:          []string{"Emily Blunt", "Meryl Streep"},
+		Direction:     []string{"Rob Marshall"},
+		Runtime:       130,
+		Rating:        -1,
+		ReleaseDate:   &release,
+		Images: []claquete.Image{
+			{URL: "/fotos/filmes/poster/8427_medio.jpg"},
+			{URL: "/fotos/filmes/galeria/8427_1.jpg"},
+		},
+	})
+
+	m, err := claquete.GetMovie(8427, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if m.Title != "O Retorno de Mary Poppins" || m.OriginalTitle != "Mary Poppins Returns" {
+		t.Fatalf("unexpected titles %s, %s", m.Title, m.OriginalTitle)
+	}
+	if m.Runtime != 130 || m.Rating != -1 || len(m.Genres) != 2 || len(m.Cast) != 2 {
+		t.Fatalf("unexpected movie %+v", m)
+	}
+	if m.ReleaseDate == nil || !m.ReleaseDate.Equal(release) {
+		t.Fatalf("expected %s, got %s", release, m.ReleaseDate)
+	}
+	if len(m.Images) != 2 || m.Images[0].Type != claquete.ImageTypePoster || m.Images[1].Type != claquete.ImageTypeAny {
+		t.Fatalf("unexpected images %+v", m.Images)
+	}
+
+	if _, err := claquete.GetMovie(1, s.Options()); err == nil {
+		t.Fatal("expected not found")
+	}
+}
+
+func TestNews(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	older := time.Date(2019, time.May, 20, 0, 0, 0, 0, loc)
+	newer := time.Date(2019, time.May, 21, 0, 0, 0, 0, loc)
+	s.AddNews(10420, claquete.News{
+		Author:   "Fernanda Mendes",
+		Content:  "Vingadores: Ultimato segue em primeiro.",
+		Headline: claquete.Headline{Title: "Vingadores: Ultimato lidera bilheteria", Category: "Bilheteria", Date: &older},
+	})
+	s.AddNews(10425, claquete.News{
+		Author:   "Fernanda Mendes",
+		Content:  "A Paris Filmes fechou contrato.",
+		Headline: claquete.Headline{Title: "Paris Filmes fecha contrato", Category: "Nacional", Date: &newer},
+	})
+	s.AddRelease(claquete.Movie{ID: 8600, Title: "Vingadores: Ultimato", ReleaseDate: &older})
+
+	headlines, err := claquete.GetHeadlines(s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(headlines) != 2 || headlines[0].Title != "Paris Filmes fecha contrato" || headlines[1].Category != "Bilheteria" {
+		t.Fatalf("unexpected headlines %+v", headlines)
+	}
+
+	n, err := headlines[0].GetNews()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if n.Author != "Fernanda Mendes" || n.Content != "A Paris Filmes fechou contrato." || len(n.Diagnostics) != 0 {
+		t.Fatalf("unexpected news %+v", n)
+	}
+
+	releases, err := claquete.NewClaquete(s.Options()).GetReleases()
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(releases) != 1 || releases[0].ID != 8600 {
+		t.Fatalf("unexpected releases %+v", releases)
+	}
+
+	cal, err := claquete.GetCalendarAt(time.May, 2019, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(cal.Weeks) != 1 || cal.Weeks[0].Date.Day() != 20 || cal.Weeks[0].Movies[0].ID != 8600 {
+		t.Fatalf("unexpected calendar %+v", cal)
+	}
+
+	results, err := claquete.Search("ultimato", s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if results.TotalCount != 1 || results.Results[0].Type != claquete.SearchTypeNews || !results.Results[0].Date.Equal(older) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
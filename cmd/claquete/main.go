@@ -0,0 +1,117 @@
+// Command claquete provides command line tools built on the claquete
+// package.
+//
+// Usage:
+//
+//	claquete health [flags]
+//
+// The health subcommand runs every scraper against known pages of the
+// website and exits with status 1 when any of them looks broken, so it
+// can be run on a schedule to detect layout changes.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	var ok bool
+	switch os.Args[1] {
+	case "health":
+		ok, err = health(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: claquete <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  health    check the scrapers against known pages")
+}
+
+func health(args []string) (bool, error) {
+	probes := claquete.DefaultHealthProbes
+
+	fs := flag.NewFlagSet("health", flag.ExitOnError)
+	fs.IntVar(&probes.Cinema, "cinema", probes.Cinema, "cinema ID whose schedule is checked")
+	fs.IntVar(&probes.Movie, "movie", probes.Movie, "movie ID checked")
+	fs.IntVar(&probes.News, "news", probes.News, "news ID checked")
+	fs.StringVar(&probes.Query, "query", probes.Query, "search query checked")
+	site := fs.String("site", claquete.BaseURL, "website base URL")
+	timeout := fs.Duration("timeout", 2*time.Minute, "timeout of the whole check")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := claquete.CheckHealthContext(ctx, probes, claquete.SiteURL(*site))
+	if err != nil {
+		return false, err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		printReport(os.Stdout, report)
+	}
+	return report.OK, err
+}
+
+func printReport(w io.Writer, report *claquete.HealthReport) {
+	for _, check := range report.Checks {
+		status := "ok"
+		if !check.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4s %-10s %s (%s)\n", status, check.Name, check.URL, check.Duration.Round(time.Millisecond))
+		if check.Error != "" {
+			fmt.Fprintf(w, "     error: %s\n", check.Error)
+		}
+		for _, sel := range check.Selectors {
+			if sel.Matches < sel.Min {
+				fmt.Fprintf(w, "     selector %q matched %d, expected at least %d\n", sel.Selector, sel.Matches, sel.Min)
+			}
+		}
+		if len(check.Empty) > 0 {
+			fmt.Fprintf(w, "     empty: %s\n", strings.Join(check.Empty, ", "))
+		}
+		for _, d := range check.Diagnostics {
+			fmt.Fprintf(w, "     warning: %s\n", d.Error())
+		}
+	}
+
+	status := "healthy"
+	if !report.OK {
+		status = "unhealthy"
+	}
+	fmt.Fprintf(w, "%s: %s (%s)\n", report.Site, status, report.Duration.Round(time.Millisecond))
+}
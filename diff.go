@@ -0,0 +1,176 @@
+package claquete
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// ScheduleDiff is the set of changes between two fetches of a
+	// schedule.
+	ScheduleDiff struct {
+		// Period is set when the period of the schedule changed.
+		Period  *PeriodChange `json:"period,omitempty"`
+		Added   []Session     `json:"added,omitempty"`
+		Removed []Session     `json:"removed,omitempty"`
+		// Moved lists the sessions that changed room or start time.
+		Moved []SessionMove `json:"moved,omitempty"`
+	}
+
+	// PeriodChange is a change in the period of a schedule.
+	PeriodChange struct {
+		Old *Period `json:"old"`
+		New *Period `json:"new"`
+	}
+
+	// SessionMove is a session that changed room or start time.
+	SessionMove struct {
+		Old Session `json:"old"`
+		New Session `json:"new"`
+	}
+)
+
+// Diff compares two fetches of a schedule. Sessions are matched by ID,
+// see Session.ID. A removed session of the same movie, format and version
+// as an added one is reported as moved when they start at the same time or
+// in the same room and day, pairing the ones starting closest first.
+// Either schedule may be nil, meaning it has no sessions.
+func Diff(prev, next *Schedule) *ScheduleDiff {
+	if prev == nil {
+		prev = &Schedule{}
+	}
+	if next == nil {
+		next = &Schedule{}
+	}
+
+	result := &ScheduleDiff{}
+	if !samePeriod(prev.Period, next.Period) {
+		result.Period = &PeriodChange{Old: prev.Period, New: next.Period}
+	}
+
+	count := make(map[string]int)
+	for _, s := range prev.Sessions {
+		count[s.ID()]++
+	}
+	var added []Session
+	for _, s := range next.Sessions {
+		k := s.ID()
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		added = append(added, s)
+	}
+	var removed []Session
+	for _, s := range prev.Sessions {
+		k := s.ID()
+		if count[k] > 0 {
+			count[k]--
+			removed = append(removed, s)
+		}
+	}
+
+	// Pair the closest sessions first, so a room change isn't mistaken
+	// for a time change of a neighbour session.
+	type pair struct {
+		i, j int
+		d    time.Duration
+	}
+	var pairs []pair
+	for i := range removed {
+		for j := range added {
+			if d, ok := moved(removed[i], added[j]); ok {
+				pairs = append(pairs, pair{i, j, d})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		return pairs[a].d < pairs[b].d
+	})
+	usedRemoved := make(map[int]bool)
+	usedAdded := make(map[int]bool)
+	for _, p := range pairs {
+		if usedRemoved[p.i] || usedAdded[p.j] {
+			continue
+		}
+		usedRemoved[p.i], usedAdded[p.j] = true, true
+		result.Moved = append(result.Moved, SessionMove{Old: removed[p.i], New: added[p.j]})
+	}
+	added = without(added, usedAdded)
+	removed = without(removed, usedRemoved)
+
+	sortSessions(added)
+	sortSessions(removed)
+	sort.SliceStable(result.Moved, func(i, j int) bool {
+		return sessionStart(result.Moved[i].Old).Before(sessionStart(result.Moved[j].Old))
+	})
+	result.Added = added
+	result.Removed = removed
+
+	return result
+}
+
+// Empty reports whether there are no changes.
+func (d *ScheduleDiff) Empty() bool {
+	return d.Period == nil && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// moved reports whether b may be a moved a, and how far apart they
+// start. Sessions moved to another room must keep their start, while
+// sessions moved to another time must keep their room and day.
+func moved(a, b Session) (time.Duration, bool) {
+	if a.CinemaID != b.CinemaID || a.MovieID != b.MovieID || a.Format != b.Format || a.Version != b.Version {
+		return 0, false
+	}
+	sa, sb := sessionStart(a), sessionStart(b)
+	if sa.Equal(sb) {
+		return 0, true
+	}
+	ya, ma, da := sa.Date()
+	yb, mb, db := sb.In(sa.Location()).Date()
+	if a.Room != b.Room || ya != yb || ma != mb || da != db {
+		return 0, false
+	}
+	d := sb.Sub(sa)
+	if d < 0 {
+		d = -d
+	}
+	return d, true
+}
+
+// without returns sessions but the ones whose index is in skip.
+func without(sessions []Session, skip map[int]bool) []Session {
+	var result []Session
+	for i, s := range sessions {
+		if !skip[i] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func samePeriod(a, b *Period) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+// sessionStart returns when s starts, or the zero time when unknown.
+func sessionStart(s Session) time.Time {
+	if s.StartTime == nil {
+		return time.Time{}
+	}
+	return *s.StartTime
+}
+
+// sortSessions sorts sessions by start time, then room.
+func sortSessions(sessions []Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		si, sj := sessionStart(sessions[i]), sessionStart(sessions[j])
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return compareRooms(sessions[i].Room, sessions[j].Room) < 0
+	})
+}
@@ -0,0 +1,102 @@
+package claquete
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	at := func(day, hours, minutes int) *time.Time {
+		t := time.Date(2019, time.May, day, hours, minutes, 0, 0, loc)
+		return &t
+	}
+
+	mary := Session{CinemaID: 656, MovieID: 8427, Format: Format3D, Version: VersionDubbed, Room: Room{Number: 1}}
+	avengers := Session{CinemaID: 656, MovieID: 8600, Format: Format2D, Version: VersionSubtitled, Room: Room{Number: 2}}
+	session := func(s Session, day, hours, minutes int) Session {
+		s.StartTime = at(day, hours, minutes)
+		return s
+	}
+
+	prev := &Schedule{
+		Period: &Period{Start: *at(23, 0, 0), End: *at(29, 0, 0)},
+		Sessions: []Session{
+			session(mary, 23, 14, 0),
+			session(mary, 24, 14, 0),
+			session(mary, 24, 21, 0),
+			session(avengers, 23, 20, 15),
+		},
+	}
+
+	roomChanged := session(avengers, 23, 20, 15)
+	roomChanged.Room = Room{Number: 3}
+	next := &Schedule{
+		Period: &Period{Start: *at(23, 0, 0), End: *at(30, 0, 0)},
+		Sessions: []Session{
+			session(mary, 23, 14, 0),
+			session(mary, 24, 21, 30),
+			session(mary, 25, 14, 0),
+			roomChanged,
+		},
+	}
+
+	diff := Diff(prev, next)
+	if diff.Empty() {
+		t.Fatal("expected changes")
+	}
+	if diff.Period == nil || !diff.Period.New.End.Equal(*at(30, 0, 0)) {
+		t.Fatalf("expected period change, got %+v", diff.Period)
+	}
+	if len(diff.Added) != 1 || !diff.Added[0].StartTime.Equal(*at(25, 14, 0)) {
+		t.Fatalf("unexpected added %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || !diff.Removed[0].StartTime.Equal(*at(24, 14, 0)) {
+		t.Fatalf("unexpected removed %+v", diff.Removed)
+	}
+	if len(diff.Moved) != 2 {
+		t.Fatalf("expected 2 moved, got %+v", diff.Moved)
+	}
+	if diff.Moved[0].Old.Room.Number != 2 || diff.Moved[0].New.Room.Number != 3 {
+		t.Fatalf("expected room change, got %+v", diff.Moved[0])
+	}
+	if !diff.Moved[1].Old.StartTime.Equal(*at(24, 21, 0)) || !diff.Moved[1].New.StartTime.Equal(*at(24, 21, 30)) {
+		t.Fatalf("expected time change, got %+v", diff.Moved[1])
+	}
+
+	b, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	var decoded ScheduleDiff
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(decoded.Added) != 1 || len(decoded.Removed) != 1 || len(decoded.Moved) != 2 || decoded.Period == nil {
+		t.Fatalf("unexpected decoded diff %s", b)
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	f, err := os.Open("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sched, err := ParseSchedule(f, "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if diff := Diff(sched, sched); !diff.Empty() {
+		t.Fatalf("expected no changes, got %+v", diff)
+	}
+
+	diff := Diff(nil, sched)
+	if len(diff.Added) != len(sched.Sessions) || diff.Period == nil {
+		t.Fatalf("expected every session added, got %d", len(diff.Added))
+	}
+}
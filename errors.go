@@ -0,0 +1,129 @@
+package claquete
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the requested movie, cinema or page
+	// doesn't exist in the website.
+	ErrNotFound = errors.New("not found")
+
+	// ErrLayoutChanged is returned when a page doesn't have the expected
+	// structure, which usually means the website markup changed.
+	ErrLayoutChanged = errors.New("layout changed")
+
+	// ErrInvalidArgument is returned when an ID, option or query is
+	// rejected before any request is made.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+type (
+	// ParseError describes a failure to parse part of a page.
+	ParseError struct {
+		// URL of the page being parsed, if known.
+		URL string
+		// Selector of the element that failed to parse.
+		Selector string
+		// Text is the raw text that couldn't be parsed.
+		Text string
+		// Err is the underlying error, usually ErrLayoutChanged or an
+		// error from a conversion.
+		Err error
+	}
+
+	// ParseErrors is a list of non-fatal parse errors, found in a result
+	// that is still usable. They are the Diagnostics of the result, or are
+	// passed to the function set with OnDiagnostics for lists.
+	ParseErrors []*ParseError
+)
+
+// Error implements error
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString("parse")
+	if e.URL != "" {
+		b.WriteString(" ")
+		b.WriteString(e.URL)
+	}
+	if e.Selector != "" {
+		fmt.Fprintf(&b, " at %q", e.Selector)
+	}
+	if e.Text != "" {
+		fmt.Fprintf(&b, " text %q", e.Text)
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON implements json.Marshaler, reporting Err by its message.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	var msg string
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		URL      string `json:"url,omitempty"`
+		Selector string `json:"selector,omitempty"`
+		Text     string `json:"text,omitempty"`
+		Error    string `json:"error"`
+	}{e.URL, e.Selector, e.Text, msg})
+}
+
+// Error implements error
+func (errs ParseErrors) Error() string {
+	switch len(errs) {
+	case 0:
+		return "no parse errors"
+	case 1:
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more parse errors)", errs[0].Error(), len(errs)-1)
+}
+
+// Unwrap returns the errors in the list
+func (errs ParseErrors) Unwrap() []error {
+	result := make([]error, len(errs))
+	for i, e := range errs {
+		result[i] = e
+	}
+	return result
+}
+
+// OnDiagnostics sets a function called with the entries skipped by the
+// operations returning lists, such as GetCinemas and GetHeadlines, whose
+// results don't carry Diagnostics. It may be called concurrently by
+// concurrent operations.
+func OnDiagnostics(f func(ParseErrors)) func(*Claquete) {
+	return func(c *Claquete) {
+		c.diagnostics = f
+	}
+}
+
+// report passes errs to the function set with OnDiagnostics, if any.
+func (c *Claquete) report(errs ParseErrors) {
+	if len(errs) > 0 && c.diagnostics != nil {
+		c.diagnostics(errs)
+	}
+}
+
+// add appends a parse error to the list.
+func (errs *ParseErrors) add(url, selector, text string, err error) {
+	*errs = append(*errs, &ParseError{
+		URL:      url,
+		Selector: selector,
+		Text:     text,
+		Err:      err,
+	})
+}
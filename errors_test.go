@@ -0,0 +1,64 @@
+package claquete
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInvalidOption(t *testing.T) {
+	c := NewClaquete(FederativeUnit("XX"))
+	if !errors.Is(c.Err(), ErrInvalidArgument) {
+		t.Fatalf("expected %s, got %v", ErrInvalidArgument, c.Err())
+	}
+
+	_, err := c.GetMovie(8427)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected %s, got %v", ErrInvalidArgument, err)
+	}
+}
+
+func TestParseScheduleNotFound(t *testing.T) {
+	pageURL := "http://claquete.com.br/programacao/1/cinema-1.html"
+	_, err := ParseSchedule(strings.NewReader("<html><body></body></html>"), pageURL)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %s, got %v", ErrNotFound, err)
+	}
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseError, got %T", err)
+	}
+	if perr.URL != pageURL {
+		t.Fatalf("expected %s, got %s", pageURL, perr.URL)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	page := `<html><body><div class="conteudo"><div class="noticias">
+<h1>Title</h1><p>Content</p></div></div></body></html>`
+	news, err := ParseNews(strings.NewReader(page), "http://claquete.com.br/noticia/1/noticia.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if news.Headline.Title != "Title" {
+		t.Fatalf("expected partial news, got %+v", news)
+	}
+
+	if len(news.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(news.Diagnostics))
+	}
+	if !errors.Is(news.Diagnostics, ErrLayoutChanged) {
+		t.Fatalf("expected %s, got %v", ErrLayoutChanged, news.Diagnostics)
+	}
+
+	b, err := json.Marshal(news.Diagnostics[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"url":"http://claquete.com.br/noticia/1/noticia.html","selector":"span:nth-child(4)","error":"layout changed"}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+}
@@ -0,0 +1,238 @@
+package claquete
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Session features
+const (
+	FeatureVIP     Feature = "vip"
+	FeatureXD      Feature = "xd"
+	FeatureIMAX    Feature = "imax"
+	FeatureDBox    Feature = "d-box"
+	FeatureMacroXE Feature = "macro_xe"
+	FeatureAtmos   Feature = "dolby_atmos"
+	FeaturePrime   Feature = "prime"
+	FeatureCinepic Feature = "cinepic"
+	FeatureKids    Feature = "kids"
+	Feature4DX     Feature = "4dx"
+
+	FeatureAudioDescription Feature = "audio_description"
+	FeatureLibras           Feature = "libras"
+	FeatureClosedCaptions   Feature = "closed_captions"
+	FeatureSensoryFriendly  Feature = "sensory_friendly"
+)
+
+// featureNames are the names of the features, as shown to users.
+var featureNames = map[Feature]string{
+	FeatureVIP:     "VIP",
+	FeatureXD:      "XD",
+	FeatureIMAX:    "IMAX",
+	FeatureDBox:    "D-BOX",
+	FeatureMacroXE: "Macro XE",
+	FeatureAtmos:   "Dolby Atmos",
+	FeaturePrime:   "Prime",
+	FeatureCinepic: "Cinépic",
+	FeatureKids:    "Kids",
+	Feature4DX:     "4DX",
+
+	FeatureAudioDescription: "Audiodescrição",
+	FeatureLibras:           "Libras",
+	FeatureClosedCaptions:   "Legenda descritiva",
+	FeatureSensoryFriendly:  "Sessão azul",
+}
+
+// accessibilityFeatures are the features meeting the needs of people with
+// disabilities.
+var accessibilityFeatures = map[Feature]bool{
+	FeatureAudioDescription: true,
+	FeatureLibras:           true,
+	FeatureClosedCaptions:   true,
+	FeatureSensoryFriendly:  true,
+}
+
+// featureHints maps words found in icon hints and notes to the features
+// they describe, in the order they are matched. Words only match whole,
+// so "prime" doesn't match "primeira".
+var featureHints = []struct {
+	word    string
+	feature Feature
+}{
+	{"audiodescrição", FeatureAudioDescription},
+	{"audiodescricao", FeatureAudioDescription},
+	{"áudio descrição", FeatureAudioDescription},
+	{"audio descrição", FeatureAudioDescription},
+	{"áudio-descrição", FeatureAudioDescription},
+	{"ad", FeatureAudioDescription},
+	{"libras", FeatureLibras},
+	{"legenda descritiva", FeatureClosedCaptions},
+	{"legendas descritivas", FeatureClosedCaptions},
+	{"closed caption", FeatureClosedCaptions},
+	{"closed captions", FeatureClosedCaptions},
+	{"sessão azul", FeatureSensoryFriendly},
+	{"sessao azul", FeatureSensoryFriendly},
+	{"sensorial", FeatureSensoryFriendly},
+	{"autismo", FeatureSensoryFriendly},
+	{"vip", FeatureVIP},
+	{"imax", FeatureIMAX},
+	{"4dx", Feature4DX},
+	{"d-box", FeatureDBox},
+	{"dbox", FeatureDBox},
+	{"macro", FeatureMacroXE},
+	{"atmos", FeatureAtmos},
+	{"prime", FeaturePrime},
+	{"cinépic", FeatureCinepic},
+	{"cinepic", FeatureCinepic},
+	{"kids", FeatureKids},
+	{"infantil", FeatureKids},
+	{"xd", FeatureXD},
+}
+
+type (
+	// Feature is a feature of a session room, such as FeatureVIP. Features
+	// not known by the package are the icon hint as shown in the schedule.
+	Feature string
+
+	// Features is a set of features, kept sorted. Sets are values: a
+	// copy isn't changed by adding features to the original.
+	Features []Feature
+)
+
+// parseFeature returns the feature described by an icon hint.
+func parseFeature(hint string) (Feature, bool) {
+	lower := strings.ToLower(hint)
+	for _, h := range featureHints {
+		if indexWord(lower, h.word) >= 0 {
+			return h.feature, true
+		}
+	}
+	return Feature(hint), false
+}
+
+// featuresIn returns every feature described in text, along with text
+// without the words describing them.
+func featuresIn(text string) (Features, string) {
+	var result Features
+	lower := strings.ToLower(text)
+	for _, h := range featureHints {
+		for i := indexWord(lower, h.word); i >= 0; i = indexWord(lower, h.word) {
+			result.Add(h.feature)
+			lower = lower[:i] + " " + lower[i+len(h.word):]
+		}
+	}
+	return result, lower
+}
+
+// indexWord returns the index of the first occurrence of word in s that
+// isn't part of a longer word, or -1 if there's none.
+func indexWord(s, word string) int {
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return -1
+		}
+		j += i
+		before, _ := utf8.DecodeLastRuneInString(s[:j])
+		after, _ := utf8.DecodeRuneInString(s[j+len(word):])
+		if !isWordRune(before) && !isWordRune(after) {
+			return j
+		}
+		_, size := utf8.DecodeRuneInString(s[j:])
+		i = j + size
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// String returns the name of the feature, as in "Dolby Atmos", or the
+// icon hint of unknown features.
+func (f Feature) String() string {
+	if name, ok := featureNames[f]; ok {
+		return name
+	}
+	return string(f)
+}
+
+// Known reports whether the feature is one of the features known by the
+// package.
+func (f Feature) Known() bool {
+	_, ok := featureNames[f]
+	return ok
+}
+
+// Accessibility reports whether the feature meets the needs of people
+// with disabilities, such as FeatureLibras.
+func (f Feature) Accessibility() bool {
+	return accessibilityFeatures[f]
+}
+
+// Has reports whether f is in the set.
+func (fs Features) Has(f Feature) bool {
+	i := sort.Search(len(fs), func(i int) bool { return fs[i] >= f })
+	return i < len(fs) && fs[i] == f
+}
+
+// Add adds f to the set. The set is copied rather than changed in place,
+// so other sets sharing its storage are left untouched.
+func (fs *Features) Add(f Feature) {
+	i := sort.Search(len(*fs), func(i int) bool { return (*fs)[i] >= f })
+	if i < len(*fs) && (*fs)[i] == f {
+		return
+	}
+	result := make(Features, len(*fs)+1)
+	copy(result, (*fs)[:i])
+	result[i] = f
+	copy(result[i+1:], (*fs)[i:])
+	*fs = result
+}
+
+// MarshalJSON encodes the session along with its ID and the room, vip,
+// xd and imax fields of previous versions.
+func (s Session) MarshalJSON() ([]byte, error) {
+	type session Session
+	return json.Marshal(struct {
+		ID string `json:"id"`
+		session
+		Room int  `json:"room"`
+		VIP  bool `json:"vip"`
+		XD   bool `json:"xd"`
+		IMAX bool `json:"imax"`
+	}{s.ID(), session(s), s.Room.Number, s.Features.Has(FeatureVIP), s.Features.Has(FeatureXD), s.Features.Has(FeatureIMAX)})
+}
+
+// UnmarshalJSON decodes the session, taking the room from the room field
+// and adding the features set in the vip, xd and imax fields of previous
+// versions.
+func (s *Session) UnmarshalJSON(b []byte) error {
+	type session Session
+	aux := struct {
+		*session
+		Room int  `json:"room"`
+		VIP  bool `json:"vip"`
+		XD   bool `json:"xd"`
+		IMAX bool `json:"imax"`
+	}{session: (*session)(s)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if s.Room == (Room{}) && aux.Room > 0 {
+		s.Room = Room{Number: aux.Room}
+	}
+	if aux.VIP {
+		s.Features.Add(FeatureVIP)
+	}
+	if aux.XD {
+		s.Features.Add(FeatureXD)
+	}
+	if aux.IMAX {
+		s.Features.Add(FeatureIMAX)
+	}
+	return nil
+}
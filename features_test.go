@@ -0,0 +1,223 @@
+package claquete
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	var fs Features
+	fs.Add(FeatureXD)
+	fs.Add(FeatureVIP)
+	fs.Add(FeatureXD)
+	if len(fs) != 2 || !fs.Has(FeatureVIP) || !fs.Has(FeatureXD) || fs.Has(FeatureIMAX) {
+		t.Fatalf("unexpected features %v", fs)
+	}
+
+	// Copies are left untouched, even when sharing spare capacity
+	base := make(Features, 0, 4)
+	base.Add(FeatureVIP)
+	copied := base
+	base.Add(FeatureAtmos)
+	copied.Add(FeatureXD)
+	if len(base) != 2 || base[0] != FeatureAtmos || len(copied) != 2 || copied[1] != FeatureXD {
+		t.Fatalf("expected independent sets, got %v and %v", base, copied)
+	}
+
+	cases := map[string]Feature{
+		"D-BOX":       FeatureDBox,
+		"Macro XE":    FeatureMacroXE,
+		"Dolby Atmos": FeatureAtmos,
+		"Sala Prime":  FeaturePrime,
+		"Cinépic":     FeatureCinepic,
+		"Sala Kids":   FeatureKids,
+		"Sala VIP":    FeatureVIP,
+		"4DX":         Feature4DX,
+	}
+	for hint, expected := range cases {
+		if f, ok := parseFeature(hint); !ok || f != expected {
+			t.Fatalf("%s: expected %s, got %s", hint, expected, f)
+		}
+	}
+	if f, ok := parseFeature("Holograma"); ok || f != "Holograma" || f.Known() {
+		t.Fatalf("expected unknown Holograma, got %s", f)
+	}
+
+	// Words inside other words aren't features
+	for _, hint := range []string{"Primeira sessão", "Sala Maxdome", "Maximax", "Sessão extra-vipe"} {
+		if f, ok := parseFeature(hint); ok {
+			t.Fatalf("%s: expected no feature, got %s", hint, f)
+		}
+		if fs, _ := featuresIn(hint); len(fs) != 0 {
+			t.Fatalf("%s: expected no features, got %v", hint, fs)
+		}
+	}
+	fs, rest := featuresIn("Primeira sessão em IMAX e 4DX")
+	if len(fs) != 2 || !fs.Has(FeatureIMAX) || !fs.Has(Feature4DX) || strings.Fields(rest)[0] != "primeira" {
+		t.Fatalf("unexpected features %v in %q", fs, rest)
+	}
+}
+
+func TestAccessibilityHints(t *testing.T) {
+	cases := map[string]Feature{
+		"Sessão AD":                     FeatureAudioDescription,
+		"Audiodescrição":                FeatureAudioDescription,
+		"Sessão com Libras":             FeatureLibras,
+		"Com legendas descritivas (CC)": FeatureClosedCaptions,
+		"Sessão Azul":                   FeatureSensoryFriendly,
+	}
+	for hint, expected := range cases {
+		if f, ok := parseFeature(hint); !ok || f != expected {
+			t.Fatalf("%s: expected %s, got %s", hint, expected, f)
+		}
+	}
+
+	// Ordinary words holding the hints
+	for _, hint := range []string{"Somente na cidade", "Versão adaptada", "Equilibras", "Legendas descritivasmente"} {
+		if fs, _ := featuresIn(hint); len(fs) != 0 {
+			t.Fatalf("%s: expected no features, got %v", hint, fs)
+		}
+	}
+}
+
+func TestSessionJSON(t *testing.T) {
+	s := Session{MovieID: 8600, Features: Features{FeatureDBox, FeatureVIP}}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if !strings.Contains(string(b), `"id":"`+s.ID()+`"`) || !strings.Contains(string(b), `"vip":true`) || !strings.Contains(string(b), `"xd":false`) || !strings.Contains(string(b), `"features":["d-box","vip"]`) {
+		t.Fatalf("unexpected JSON %s", b)
+	}
+
+	var decoded Session
+	if err := json.Unmarshal([]byte(`{"movie_id":8600,"vip":true,"imax":true}`), &decoded); err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if decoded.MovieID != 8600 || !decoded.Features.Has(FeatureVIP) || !decoded.Features.Has(FeatureIMAX) {
+		t.Fatalf("unexpected session %+v", decoded)
+	}
+
+	// The room field keeps the number, the rest is in room_info
+	s.Room = Room{Label: "Sala VIP 2", Number: 2, Kind: RoomVIP}
+	b, err = json.Marshal(s)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if !strings.Contains(string(b), `"room":2`) || !strings.Contains(string(b), `"room_info":{"label":"Sala VIP 2","number":2,"kind":"vip"}`) {
+		t.Fatalf("unexpected JSON %s", b)
+	}
+	decoded = Session{}
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded.Room != s.Room {
+		t.Fatalf("expected room %+v, got %+v (%v)", s.Room, decoded.Room, err)
+	}
+	decoded = Session{}
+	if err := json.Unmarshal([]byte(`{"movie_id":8600,"room":3}`), &decoded); err != nil || decoded.Room.Number != 3 {
+		t.Fatalf("expected room 3, got %+v (%v)", decoded.Room, err)
+	}
+}
+
+func TestParseScheduleFeatures(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(`data-hint="Sala VIP"`, `data-hint="Sala VIP"></div><div data-hint="D-BOX"></div><div data-hint="Holograma"`).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	last := sched.Sessions[len(sched.Sessions)-1]
+	expected := Features{"Holograma", FeatureDBox, FeatureVIP}
+	if len(last.Features) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, last.Features)
+	}
+	for i, f := range expected {
+		if last.Features[i] != f {
+			t.Fatalf("expected %v, got %v", expected, last.Features)
+		}
+	}
+}
+
+func TestParseScheduleAccessibility(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		`data-hint="Sala VIP"`, `data-hint="Sala VIP"></div><div data-hint="Legenda descritiva"></div><div data-hint="Audiodescrição"`,
+		`<div data-hint="Exceto Qua. (22/05)"><span class="hleter">B</span></div>`,
+		`<div data-hint="Exceto Qua. (22/05)"><span class="hleter">B</span></div>
+			<div data-hint="Sessão com Libras e audiodescrição"><span class="hleter">C</span></div>`,
+		"17h30A", "17h30AC",
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(sched.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", sched.Diagnostics)
+	}
+
+	last := sched.Sessions[len(sched.Sessions)-1]
+	if last.Version != VersionSubtitled || !last.Features.Has(FeatureClosedCaptions) || !last.Features.Has(FeatureAudioDescription) {
+		t.Fatalf("unexpected session %+v", last)
+	}
+
+	if n := len(sched.Query(WithAccessibility(FeatureLibras, FeatureAudioDescription))); n != 2 {
+		t.Fatalf("expected 2 sessions with Libras and audio description, got %d", n)
+	}
+	if n := len(sched.Query(WithAccessibility(FeatureAudioDescription))); n != 9 {
+		t.Fatalf("expected 9 sessions with audio description, got %d", n)
+	}
+	if n := len(sched.Query(Not(WithAccessibility()))); n != 13 {
+		t.Fatalf("expected 13 sessions without accessibility features, got %d", n)
+	}
+}
+
+func TestParseScheduleNoteFeatures(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		`data-hint="Sala VIP"`, `data-hint="D-BOX"></div><div data-hint="Dolby Atmos"></div><div data-hint="Sala VIP"`,
+		"Somente Sáb. (18/05) Dom. (19/05)", "Sessão com audiodescrição",
+		"Sala 2 20h15", "Sala 2 18h00A, 20h15",
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	var described, others int
+	for _, s := range sched.Sessions {
+		if s.MovieID != 8600 {
+			continue
+		}
+		if !s.Features.Has(FeatureVIP) || !s.Features.Has(FeatureDBox) || !s.Features.Has(FeatureAtmos) {
+			t.Fatalf("expected VIP, D-BOX and Dolby Atmos in %+v", s)
+		}
+		switch s.StartTime.Hour() {
+		case 18:
+			described++
+			if !s.Features.Has(FeatureAudioDescription) {
+				t.Fatalf("expected audio description in %+v", s)
+			}
+		case 20:
+			others++
+			if s.Features.Has(FeatureAudioDescription) {
+				t.Fatalf("expected no audio description in %+v", s)
+			}
+		}
+	}
+	if described != 7 || others != 7 {
+		t.Fatalf("expected 7 sessions at each time, got %d and %d", described, others)
+	}
+}
@@ -0,0 +1,70 @@
+package claquete
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/dsbezerra/claqueteapi/httpreplay"
+)
+
+const fixturesDir = "testdata/fixtures"
+
+var record = flag.Bool("record", false, "record website responses into "+fixturesDir)
+
+// fixtures returns the option used by tests that talk to the website.
+// They replay the responses in fixturesDir, or record them when -record
+// is given. Tests are skipped when there are no recordings, so they never
+// reach the website unless asked to.
+func fixtures(t *testing.T) Options {
+	if *record {
+		return RecordFixtures(fixturesDir)
+	}
+	if _, err := os.Stat(fixturesDir); err != nil {
+		t.Skipf("no recordings in %s, run go test -record to record them", fixturesDir)
+	}
+	return ReplayFixtures(fixturesDir)
+}
+
+func TestRecordReplay(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/busca.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recorded, err := NewClaquete(SiteURL(ts.URL), RecordFixtures(dir)).Search("vingadores")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	ts.Close()
+
+	// Search sends random x and y fields, which must not matter
+	c := NewClaquete(SiteURL(ts.URL), ReplayFixtures(dir))
+	replayed, err := c.Search("vingadores")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if replayed.TotalCount != recorded.TotalCount || len(replayed.Results) != len(recorded.Results) {
+		t.Fatalf("expected %+v, got %+v", recorded, replayed)
+	}
+
+	_, err = c.Search("cinemais")
+	if !errors.Is(err, httpreplay.ErrNoFixture) {
+		t.Fatalf("expected %s, got %v", httpreplay.ErrNoFixture, err)
+	}
+}
@@ -0,0 +1,365 @@
+package claquete
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dsbezerra/claqueteapi/util"
+	"github.com/gocolly/colly"
+)
+
+type (
+	// HealthProbes are the known pages used to check the scrapers. They
+	// must exist in the website for the check to be meaningful.
+	HealthProbes struct {
+		Cinema int    `json:"cinema"`
+		Movie  int    `json:"movie"`
+		News   int    `json:"news"`
+		Query  string `json:"query"`
+	}
+
+	// HealthReport is the result of running every scraper against the
+	// website probes.
+	HealthReport struct {
+		Site     string        `json:"site"`
+		Started  time.Time     `json:"started"`
+		Duration time.Duration `json:"duration"`
+		OK       bool          `json:"ok"`
+		Checks   []HealthCheck `json:"checks"`
+	}
+
+	// HealthCheck is the result of checking a single page. It is OK when
+	// the page was retrieved, every selector matched at least Min times and
+	// no expected field came back empty.
+	HealthCheck struct {
+		Name      string          `json:"name"`
+		URL       string          `json:"url"`
+		OK        bool            `json:"ok"`
+		Error     string          `json:"error,omitempty"`
+		Selectors []SelectorMatch `json:"selectors"`
+		// Empty lists the fields the parser left empty although they are
+		// expected to be filled for the probe.
+		Empty       []string      `json:"empty,omitempty"`
+		Diagnostics ParseErrors   `json:"diagnostics,omitempty"`
+		Duration    time.Duration `json:"duration"`
+	}
+
+	// SelectorMatch tells how many elements a selector used by the
+	// scrapers matched in a page.
+	SelectorMatch struct {
+		Selector string `json:"selector"`
+		Matches  int    `json:"matches"`
+		Min      int    `json:"min"`
+	}
+
+	// healthProbe describes how to check a page.
+	healthProbe struct {
+		name      string
+		url       string
+		form      map[string]string
+		selectors []SelectorMatch
+		check     func(body []byte, pageURL string, empty func(field string, isEmpty bool)) (ParseErrors, error)
+	}
+)
+
+// DefaultHealthProbes are long-lived pages of the website.
+var DefaultHealthProbes = HealthProbes{
+	Cinema: 656,   // Cinemais Montes Claros
+	Movie:  8427,  // O Retorno de Mary Poppins
+	News:   10425, // Paris Filmes fecha contrato para filme sobre Ney Matogrosso
+	Query:  "vingadores",
+}
+
+// CheckHealth runs every scraper against the given probes and reports
+// which selectors matched and which fields came back empty.
+func CheckHealth(probes HealthProbes, options ...Options) (*HealthReport, error) {
+	return CheckHealthContext(context.Background(), probes, options...)
+}
+
+// CheckHealthContext is like CheckHealth but aborts when ctx is done.
+func CheckHealthContext(ctx context.Context, probes HealthProbes, options ...Options) (*HealthReport, error) {
+	return NewClaquete(options...).CheckHealthContext(ctx, probes)
+}
+
+// CheckHealth runs every scraper against the given probes and reports
+// which selectors matched and which fields came back empty.
+func (c *Claquete) CheckHealth(probes HealthProbes) (*HealthReport, error) {
+	return c.CheckHealthContext(context.Background(), probes)
+}
+
+// CheckHealthContext is like CheckHealth but aborts when ctx is done.
+// Pages are always retrieved from the website, bypassing the response
+// cache.
+func (c *Claquete) CheckHealthContext(ctx context.Context, probes HealthProbes) (*HealthReport, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result := &HealthReport{
+		Site:    c.baseURL,
+		Started: time.Now(),
+		OK:      true,
+	}
+
+	for _, p := range c.healthProbes(probes) {
+		check := c.runProbe(ctx, p)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !check.OK {
+			result.OK = false
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	result.Duration = time.Since(result.Started)
+
+	return result, nil
+}
+
+func (c *Claquete) runProbe(ctx context.Context, p healthProbe) HealthCheck {
+	start := time.Now()
+	result := HealthCheck{
+		Name: p.name,
+		URL:  p.url,
+	}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	body, pageURL, err := c.fetch(ctx, p.url, p.form)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.URL = pageURL
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.OK = true
+	for _, sel := range p.selectors {
+		sel.Matches = doc.Find(sel.Selector).Length()
+		if sel.Matches < sel.Min {
+			result.OK = false
+		}
+		result.Selectors = append(result.Selectors, sel)
+	}
+
+	empty := func(field string, isEmpty bool) {
+		if isEmpty {
+			result.Empty = append(result.Empty, field)
+			result.OK = false
+		}
+	}
+	result.Diagnostics, err = p.check(body, pageURL, empty)
+	if err != nil {
+		result.Error = err.Error()
+		result.OK = false
+	}
+
+	return result
+}
+
+// fetch retrieves a page bypassing the response cache, returning its
+// body and the URL it was retrieved from. Pages are posted form when it
+// isn't nil.
+func (c *Claquete) fetch(ctx context.Context, u string, form map[string]string) ([]byte, string, error) {
+	var body []byte
+	var pageURL string
+
+	collector := c.collect(ctx)
+	collector.WithTransport(&contextTransport{ctx: ctx, base: c.site})
+	collector.OnResponse(func(r *colly.Response) {
+		body = r.Body
+		pageURL = r.Request.URL.String()
+	})
+
+	var err error
+	if form != nil {
+		err = collector.Post(u, form)
+	} else {
+		err = collector.Visit(u)
+	}
+	if ctx.Err() != nil {
+		return nil, "", ctx.Err()
+	}
+	return body, pageURL, err
+}
+
+func (c *Claquete) healthProbes(probes HealthProbes) []healthProbe {
+	loc := util.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+
+	cinema := selScheduleCinema(probes.Cinema)
+	movies := cinema + " " + selMovieSessions
+
+	return []healthProbe{
+		{
+			name: "schedule",
+			url:  c.url(fmt.Sprintf("/programacao/%d/cinema-%d.html", probes.Cinema, probes.Cinema)),
+			selectors: []SelectorMatch{
+				{Selector: selSchedule, Min: 1},
+				{Selector: selSchedule + " > " + selScheduleState, Min: 1},
+				{Selector: cinema, Min: 1},
+				{Selector: selSchedule + " " + selCinemaName, Min: 1},
+				{Selector: selSchedule + " " + selCinemaAddress, Min: 1},
+				{Selector: cinema + " " + selPeriod, Min: 2},
+				{Selector: movies, Min: 1},
+				{Selector: movies + " " + selSessionsMovie, Min: 1},
+				{Selector: movies + " " + selSessionsRooms, Min: 1},
+				{Selector: movies + " " + selSessionsIcons, Min: 1},
+				{Selector: cinema + " " + selNotes},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				sched, err := ParseSchedule(bytes.NewReader(body), pageURL)
+				if err != nil {
+					return nil, err
+				}
+				empty("cinema.name", sched.Cinema.Name == "")
+				empty("cinema.address_line", sched.Cinema.AddressLine == "")
+				empty("cinema.time_zone", sched.Cinema.TimeZone == "")
+				empty("period", sched.Period == nil)
+				empty("sessions", len(sched.Sessions) == 0)
+				return sched.Diagnostics, nil
+			},
+		},
+		{
+			name: "movie",
+			url:  c.url("/filmes/filme.php?cf=" + strconv.Itoa(probes.Movie)),
+			selectors: []SelectorMatch{
+				{Selector: selMovieRating, Min: 1},
+				{Selector: selMovieDesc, Min: 1},
+				{Selector: selMovieDesc + " " + selMovieTitle, Min: 1},
+				{Selector: selMovieDesc + " " + selMovieOrig, Min: 1},
+				{Selector: selMovieDesc + " " + selMovieInfo, Min: 1},
+				{Selector: selMovieCast + " > h3", Min: 1},
+				{Selector: selMovieImages, Min: 1},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				m, err := ParseMovie(bytes.NewReader(body), pageURL)
+				if err != nil {
+					return nil, err
+				}
+				empty("title", m.Title == "")
+				empty("original_title", m.OriginalTitle == "")
+				empty("synopsis", m.Synopsis == "")
+				empty("country", m.Country == "")
+				empty("genres", len(m.Genres) == 0)
+				empty("cast", len(m.Cast) == 0)
+				empty("direction", len(m.Direction) == 0)
+				empty("runtime", m.Runtime == 0)
+				empty("rating", m.Rating == 0)
+				empty("release_date", m.ReleaseDate == nil)
+				empty("images", len(m.Images) == 0)
+				return m.Diagnostics, nil
+			},
+		},
+		{
+			name: "headlines",
+			url:  c.url("/noticias.html"),
+			selectors: []SelectorMatch{
+				{Selector: selNews, Min: 1},
+				{Selector: selNews + " > " + selHeadlineImage, Min: 1},
+				{Selector: selNews + " > " + selHeadlineDate, Min: 1},
+				{Selector: selNews + " > " + selHeadlineTitle + " a", Min: 1},
+				{Selector: selReleases, Min: 1},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				headlines, errs, err := parseHeadlines(bytes.NewReader(body), pageURL)
+				if err != nil {
+					return nil, err
+				}
+				empty("headlines", len(headlines) == 0)
+				if len(headlines) > 0 {
+					empty("headlines.image", headlines[0].Image == "")
+					empty("headlines.category", headlines[0].Category == "")
+				}
+				return errs, nil
+			},
+		},
+		{
+			name: "news",
+			url:  c.url(fmt.Sprintf("/noticia/%d/noticia.html", probes.News)),
+			selectors: []SelectorMatch{
+				{Selector: selNews, Min: 1},
+				{Selector: selNews + " " + selNewsDate, Min: 1},
+				{Selector: selNews + " " + selNewsAuthor, Min: 1},
+				{Selector: selNews + " " + selNewsTitle, Min: 1},
+				{Selector: selNews + " " + selNewsContent, Min: 1},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				n, err := ParseNews(bytes.NewReader(body), pageURL)
+				if err != nil {
+					return nil, err
+				}
+				empty("author", n.Author == "")
+				empty("content", n.Content == "")
+				empty("headline.title", n.Headline.Title == "")
+				empty("headline.date", n.Headline.Date == nil)
+				return n.Diagnostics, nil
+			},
+		},
+		{
+			name: "search",
+			url:  c.url("/busca.html"),
+			form: map[string]string{"query": probes.Query},
+			selectors: []SelectorMatch{
+				{Selector: selSearch, Min: 1},
+				{Selector: selSearch + " > h3", Min: 1},
+				{Selector: selSearch + " > div.ttsubn", Min: 1},
+				{Selector: selSearch + " > h2 a", Min: 1},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				results, err := ParseSearchResults(bytes.NewReader(body), probes.Query, DefaultSearchFilterFlags)
+				if err != nil {
+					return nil, err
+				}
+				empty("total_count", results.TotalCount == 0)
+				empty("results", len(results.Results) == 0)
+				return results.Diagnostics, nil
+			},
+		},
+		{
+			name: "calendar",
+			url:  c.ajax("calendario.php"),
+			form: map[string]string{
+				"ano": strconv.Itoa(now.Year()),
+				"mes": strconv.Itoa(int(now.Month())),
+			},
+			selectors: []SelectorMatch{
+				{Selector: selCalendarWeek + " " + selCalendarDay, Min: 1},
+				{Selector: selCalendarPosters, Min: 1},
+				{Selector: selCalendarPosters + " " + selCalendarSynopsis, Min: 1},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				cal, err := ParseCalendar(bytes.NewReader(body), now.Month(), now.Year())
+				if err != nil {
+					return nil, err
+				}
+				empty("weeks", len(cal.Weeks) == 0)
+				if len(cal.Weeks) > 0 {
+					empty("weeks.date", cal.Weeks[0].Date.IsZero())
+					empty("weeks.movies", len(cal.Weeks[0].Movies) == 0)
+				}
+				return cal.Diagnostics, nil
+			},
+		},
+		{
+			name: "states",
+			url:  c.url(""),
+			selectors: []SelectorMatch{
+				{Selector: selStates, Min: len(FederativeUnits)},
+			},
+			check: func(body []byte, pageURL string, empty func(string, bool)) (ParseErrors, error) {
+				return nil, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package claquete_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+	"github.com/dsbezerra/claqueteapi/claquetetest"
+)
+
+func newHealthySite() *claquetetest.Server {
+	s := claquetetest.NewServer()
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today.Add(14 * time.Hour)
+
+	city := claquete.City{Name: "Montes Claros", State: claquete.State{FU: claquete.MG, Name: claquete.MinasGerais}}
+	cinema := claquete.Cinema{ID: 656, Name: "Cinemais Montes Claros", AddressLine: "Av. Donato Quintino, 90"}
+	s.AddCinema(city, cinema)
+	s.AddSchedule(claquete.Schedule{
+		Cinema: &cinema,
+		Sessions: []claquete.Session{{
+			MovieID:    8427,
+			MovieTitle: "O Retorno de Mary Poppins",
+			Version:    claquete.VersionDubbed,
+			Room:       claquete.Room{Label: "Sala 1", Number: 1},
+			StartTime:  &start,
+		}},
+	})
+
+	movie := claquete.Movie{
+		ID:            8427,
+		Title:         "O Retorno de Mary Poppins",
+		OriginalTitle: "Mary Poppins Returns",
+		Synopsis:      "Michael Banks perde a esposa.",
+		Country:       "EUA",
+		Genres:        []string{"Família"},
+		Cast:          []string{"Emily Blunt"},
+		Direction:     []string{"Rob Marshall"},
+		Runtime:       130,
+		Rating:        -1,
+		ReleaseDate:   &today,
+		Images:        []claquete.Image{{URL: "/fotos/filmes/poster/8427_medio.jpg"}},
+	}
+	s.AddMovie(movie)
+	s.AddRelease(movie)
+
+	for i, title := range []string{"Vingadores: Ultimato lidera bilheteria", "Paris Filmes fecha contrato"} {
+		date := today.AddDate(0, 0, -i)
+		s.AddNews(10425+i, claquete.News{
+			Author:   "Fernanda Mendes",
+			Content:  "Conteúdo.",
+			Headline: claquete.Headline{Title: title, Category: "Nacional", Image: "/fotos/noticias/1.jpg", Date: &date},
+		})
+	}
+
+	return s
+}
+
+func TestHealthCheck(t *testing.T) {
+	s := newHealthySite()
+	defer s.Close()
+
+	report, err := claquete.CheckHealth(claquete.DefaultHealthProbes, s.Options())
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if !report.OK {
+		for _, check := range report.Checks {
+			if !check.OK {
+				t.Errorf("unexpected failed check %+v", check)
+			}
+		}
+		t.FailNow()
+	}
+
+	expected := 7
+	if len(report.Checks) != expected {
+		t.Fatalf("expected %d checks, got %d", expected, len(report.Checks))
+	}
+}
+
+func TestHealthCheckLayoutChanged(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><div class="content"></div></body></html>`))
+	}))
+	defer ts.Close()
+
+	report, err := claquete.CheckHealth(claquete.DefaultHealthProbes, claquete.SiteURL(ts.URL))
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if report.OK {
+		t.Fatal("expected unhealthy report")
+	}
+
+	schedule := report.Checks[0]
+	if schedule.Name != "schedule" || schedule.OK || schedule.Error == "" {
+		t.Fatalf("unexpected check %+v", schedule)
+	}
+	if schedule.Selectors[0].Matches != 0 {
+		t.Fatalf("expected no matches, got %d", schedule.Selectors[0].Matches)
+	}
+}
@@ -0,0 +1,71 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Disk is a Cache that keeps each entry as a JSON file in a directory,
+// so cached responses survive restarts.
+type Disk struct {
+	dir string
+}
+
+// NewDisk creates a filesystem cache in dir, creating it if needed.
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir}, nil
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements Cache
+func (d *Disk) Get(key string) (*Entry, bool) {
+	data, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+// Set implements Cache
+func (d *Disk) Set(key string, e *Entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	// Write to a temporary file first so readers never see partial entries.
+	tmp, err := ioutil.TempFile(d.dir, ".entry-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete implements Cache
+func (d *Disk) Delete(key string) {
+	os.Remove(d.path(key))
+}
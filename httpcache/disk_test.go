@@ -0,0 +1,44 @@
+package httpcache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Now().Add(time.Hour).Round(0)
+	c.Set("GET http://claquete.com.br/", &Entry{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": {`"v1"`}},
+		Body:       []byte("content"),
+		Expires:    expires,
+	})
+
+	e, ok := c.Get("GET http://claquete.com.br/")
+	if !ok {
+		t.Fatal("expected entry in cache")
+	}
+
+	if string(e.Body) != "content" || e.Header.Get("ETag") != `"v1"` || !e.Expires.Equal(expires) {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+
+	c.Delete("GET http://claquete.com.br/")
+	if _, ok := c.Get("GET http://claquete.com.br/"); ok {
+		t.Fatal("expected entry to be deleted")
+	}
+}
@@ -0,0 +1,214 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Cache stores responses by key.
+	//
+	// Implementations must be safe for concurrent use.
+	Cache interface {
+		Get(key string) (*Entry, bool)
+		Set(key string, e *Entry)
+		Delete(key string)
+	}
+
+	// Entry is a cached response.
+	Entry struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       []byte      `json:"body"`
+		Stored     time.Time   `json:"stored"`
+		Expires    time.Time   `json:"expires"`
+	}
+
+	// Transport is a http.RoundTripper that serves responses from a Cache
+	// while they are fresh, revalidating them with ETag and Last-Modified
+	// once they expire.
+	Transport struct {
+		// Cache where responses are stored.
+		Cache Cache
+
+		// Base is the transport used to make requests. If nil,
+		// http.DefaultTransport is used.
+		Base http.RoundTripper
+
+		// TTL returns for how long the response to a request is fresh.
+		// Requests with TTL zero or less bypass the cache. If nil, nothing
+		// is cached.
+		TTL func(req *http.Request) time.Duration
+
+		// Key returns the cache key of a request given its body. If nil,
+		// DefaultKey is used.
+		Key func(req *http.Request, body []byte) string
+
+		// StaleIfError makes the transport serve an expired response when
+		// the request fails or the server answers with a 5xx status.
+		StaleIfError bool
+	}
+)
+
+const (
+	// XCache is the header set in responses to tell whether they were
+	// served from cache.
+	XCache = "X-Cache"
+
+	// Hit means the response was fresh in cache.
+	Hit = "HIT"
+	// Revalidated means the server confirmed the cached response.
+	Revalidated = "REVALIDATED"
+	// Stale means the response expired but was served due to an error.
+	Stale = "STALE"
+	// Miss means the response came from the server.
+	Miss = "MISS"
+)
+
+// DefaultKey builds a key from request method, URL and body.
+func DefaultKey(req *http.Request, body []byte) string {
+	key := req.Method + " " + req.URL.String()
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		key += " " + hex.EncodeToString(sum[:])
+	}
+	return key
+}
+
+// Fresh reports whether the entry can be served without revalidation.
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// response builds a http.Response for req from the entry.
+func (e *Entry) response(req *http.Request, status string) *http.Response {
+	header := make(http.Header, len(e.Header)+1)
+	for k, v := range e.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set(XCache, status)
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var ttl time.Duration
+	if t.TTL != nil {
+		ttl = t.TTL(req)
+	}
+	if ttl <= 0 || t.Cache == nil {
+		return t.base().RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	keyFunc := t.Key
+	if keyFunc == nil {
+		keyFunc = DefaultKey
+	}
+	key := keyFunc(req, body)
+
+	now := time.Now()
+	cached, ok := t.Cache.Get(key)
+	if ok && cached.Fresh(now) {
+		return cached.response(req, Hit), nil
+	}
+
+	outReq := req
+	if ok {
+		etag := cached.Header.Get("ETag")
+		lastModified := cached.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			outReq = req.Clone(req.Context())
+			if body != nil {
+				outReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			if etag != "" {
+				outReq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				outReq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := t.base().RoundTrip(outReq)
+	if err != nil {
+		if ok && t.StaleIfError && req.Context().Err() == nil {
+			return cached.response(req, Stale), nil
+		}
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		drain(resp.Body)
+		refreshed := *cached
+		refreshed.Expires = now.Add(ttl)
+		t.Cache.Set(key, &refreshed)
+		return refreshed.response(req, Revalidated), nil
+	}
+
+	if ok && t.StaleIfError && resp.StatusCode >= 500 {
+		drain(resp.Body)
+		return cached.response(req, Stale), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       data,
+		Stored:     now,
+		Expires:    now.Add(ttl),
+	}
+	t.Cache.Set(key, entry)
+
+	return entry.response(req, Miss), nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// drain discards what is left of body so the connection can be reused.
+func drain(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
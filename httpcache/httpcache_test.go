@@ -0,0 +1,165 @@
+package httpcache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, client *http.Client, u string) (string, string) {
+	resp, err := client.Get(u)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), resp.Header.Get(XCache)
+}
+
+func TestTransportHit(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		fmt.Fprintf(w, "response %d", n)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Cache: NewLRU(10),
+		TTL:   func(*http.Request) time.Duration { return time.Minute },
+	}}
+
+	body, status := get(t, client, ts.URL)
+	if body != "response 1" || status != Miss {
+		t.Fatalf("expected response 1 (%s), got %s (%s)", Miss, body, status)
+	}
+
+	body, status = get(t, client, ts.URL)
+	if body != "response 1" || status != Hit {
+		t.Fatalf("expected response 1 (%s), got %s (%s)", Hit, body, status)
+	}
+
+	if hits != 1 {
+		t.Fatalf("expected 1 request to server, got %d", hits)
+	}
+}
+
+func TestTransportPostKey(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		r.ParseForm()
+		fmt.Fprint(w, r.PostForm.Get("mes"))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Cache: NewLRU(10),
+		TTL:   func(*http.Request) time.Duration { return time.Minute },
+	}}
+
+	for _, mes := range []string{"1", "2", "1"} {
+		resp, err := client.Post(ts.URL, "application/x-www-form-urlencoded", strings.NewReader("mes="+mes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != mes {
+			t.Fatalf("expected %s, got %s", mes, body)
+		}
+	}
+
+	if hits != 2 {
+		t.Fatalf("expected 2 requests to server, got %d", hits)
+	}
+}
+
+func TestTransportRevalidate(t *testing.T) {
+	var hits, notModified int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "content")
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Cache: NewLRU(10),
+		TTL:   func(*http.Request) time.Duration { return time.Nanosecond },
+	}}
+
+	get(t, client, ts.URL)
+	time.Sleep(time.Millisecond)
+	body, status := get(t, client, ts.URL)
+	if body != "content" || status != Revalidated {
+		t.Fatalf("expected content (%s), got %s (%s)", Revalidated, body, status)
+	}
+
+	if hits != 2 || notModified != 1 {
+		t.Fatalf("expected 2 requests and 1 not modified, got %d and %d", hits, notModified)
+	}
+}
+
+func TestTransportStaleIfError(t *testing.T) {
+	var fail int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "content")
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Cache:        NewLRU(10),
+		TTL:          func(*http.Request) time.Duration { return time.Nanosecond },
+		StaleIfError: true,
+	}}
+
+	get(t, client, ts.URL)
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(time.Millisecond)
+
+	body, status := get(t, client, ts.URL)
+	if body != "content" || status != Stale {
+		t.Fatalf("expected content (%s), got %s (%s)", Stale, body, status)
+	}
+
+	ts.Close()
+	body, status = get(t, client, ts.URL)
+	if body != "content" || status != Stale {
+		t.Fatalf("expected content (%s), got %s (%s)", Stale, body, status)
+	}
+}
+
+func TestTransportBypass(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer ts.Close()
+
+	cache := NewLRU(10)
+	client := &http.Client{Transport: &Transport{Cache: cache}}
+
+	get(t, client, ts.URL)
+	get(t, client, ts.URL)
+	if hits != 2 || cache.Len() != 0 {
+		t.Fatalf("expected 2 requests and empty cache, got %d and %d", hits, cache.Len())
+	}
+}
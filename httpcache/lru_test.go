@@ -0,0 +1,34 @@
+package httpcache
+
+import (
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", &Entry{Body: []byte("a")})
+	c.Set("b", &Entry{Body: []byte("b")})
+
+	// Touch a so b becomes the least recently used.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a in cache")
+	}
+
+	c.Set("c", &Entry{Body: []byte("c")})
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+
+	if e, ok := c.Get("a"); !ok || string(e.Body) != "a" {
+		t.Fatal("expected a in cache")
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected a to be deleted")
+	}
+
+	if c.Len() != 1 {
+		t.Fatalf("expected size %d, got %d", 1, c.Len())
+	}
+}
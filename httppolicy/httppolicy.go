@@ -0,0 +1,325 @@
+// Package httppolicy provides a http.RoundTripper that is polite to the
+// website it talks to: it limits concurrency and request rate per host,
+// retries failed requests with exponential backoff and stops reaching a
+// host that keeps failing.
+package httppolicy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrCircuitOpen is returned without reaching the host while its circuit
+// is open after too many consecutive failures.
+var ErrCircuitOpen = errors.New("httppolicy: circuit open")
+
+type (
+	// Transport is a http.RoundTripper applying a politeness and retry
+	// policy to the requests made with Base. Its zero value makes requests
+	// without any limit, retry or circuit breaker.
+	//
+	// Fields must not be changed once the Transport is in use.
+	Transport struct {
+		// Base is the transport used to make requests. If nil,
+		// http.DefaultTransport is used.
+		Base http.RoundTripper
+
+		// Concurrency is the maximum number of requests in flight to the
+		// same host. Zero means no limit.
+		Concurrency int
+
+		// Delay is the minimum time between the start of two requests to
+		// the same host.
+		Delay time.Duration
+
+		// Jitter is the maximum random time added to Delay.
+		Jitter time.Duration
+
+		// Retries is how many times a request is retried after a 429 or
+		// 5xx status, a timeout or a connection reset.
+		Retries int
+
+		// MinBackoff is the wait before the first retry, doubled on each
+		// following one. Defaults to 500ms.
+		MinBackoff time.Duration
+
+		// MaxBackoff caps the wait between retries, including the one
+		// asked by a Retry-After header. Defaults to 30s.
+		MaxBackoff time.Duration
+
+		// Threshold is the number of consecutive failed requests, after
+		// retries, that opens the circuit of a host. Zero disables the
+		// circuit breaker.
+		Threshold int
+
+		// Cooldown is for how long the circuit stays open before a single
+		// request is let through to probe the host. Defaults to 30s.
+		Cooldown time.Duration
+
+		mu    sync.Mutex
+		hosts map[string]*host
+	}
+
+	// host is the state kept for each host reached by a Transport.
+	host struct {
+		sem chan struct{}
+
+		mu       sync.Mutex
+		next     time.Time
+		failures int
+		openedAt time.Time
+		probing  bool
+	}
+)
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.host(req.URL.Host)
+	if !h.allow(t.Threshold, t.cooldown()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrCircuitOpen
+	}
+
+	if t.Retries > 0 && req.Body != nil && req.GetBody == nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			h.release()
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	for attempt := 0; ; attempt++ {
+		outReq := req
+		if attempt > 0 && req.GetBody != nil {
+			outReq = req.Clone(req.Context())
+			body, err := req.GetBody()
+			if err != nil {
+				h.release()
+				return nil, err
+			}
+			outReq.Body = body
+		}
+
+		resp, err := t.send(h, outReq)
+		if req.Context().Err() != nil {
+			// Canceled requests tell nothing about the host health.
+			h.release()
+			return resp, err
+		}
+		if attempt >= t.Retries || !retryable(resp, err) {
+			h.done(err == nil && resp.StatusCode < 500)
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			drain(resp.Body)
+		}
+		if err := sleep(req, wait); err != nil {
+			h.release()
+			return nil, err
+		}
+	}
+}
+
+// send makes a single request once the host allows it.
+func (t *Transport) send(h *host, req *http.Request) (*http.Response, error) {
+	if err := t.wait(h, req); err != nil {
+		return nil, err
+	}
+	if h.sem != nil {
+		defer func() { <-h.sem }()
+	}
+	return t.base().RoundTrip(req)
+}
+
+// wait blocks until a request to h can start, honoring Concurrency and
+// Delay.
+func (t *Transport) wait(h *host, req *http.Request) error {
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+		case <-req.Context().Done():
+			return req.Context().Err()
+		}
+	}
+
+	if t.Delay <= 0 && t.Jitter <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	gap := t.Delay
+	if t.Jitter > 0 {
+		gap += time.Duration(rand.Int63n(int64(t.Jitter)))
+	}
+	h.next = start.Add(gap)
+	h.mu.Unlock()
+
+	if err := sleep(req, start.Sub(now)); err != nil {
+		if h.sem != nil {
+			<-h.sem
+		}
+		return err
+	}
+	return nil
+}
+
+// backoff returns how long to wait before retrying the given attempt.
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	max := t.MaxBackoff
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			d := time.Duration(s) * time.Second
+			if d > max {
+				d = max
+			}
+			return d
+		}
+	}
+
+	d := t.MinBackoff
+	if d <= 0 {
+		d = 500 * time.Millisecond
+	}
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// Spread retries of concurrent requests between d/2 and d.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (t *Transport) host(name string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hosts == nil {
+		t.hosts = make(map[string]*host)
+	}
+	h, ok := t.hosts[name]
+	if !ok {
+		h = &host{}
+		if t.Concurrency > 0 {
+			h.sem = make(chan struct{}, t.Concurrency)
+		}
+		t.hosts[name] = h
+	}
+	return h
+}
+
+func (t *Transport) cooldown() time.Duration {
+	if t.Cooldown <= 0 {
+		return 30 * time.Second
+	}
+	return t.Cooldown
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// allow reports whether a request can be made to the host. Once the
+// cooldown of an open circuit ends, only one request is let through until
+// it is done.
+func (h *host) allow(threshold int, cooldown time.Duration) bool {
+	if threshold <= 0 {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failures < threshold {
+		return true
+	}
+	if h.probing || time.Since(h.openedAt) < cooldown {
+		return false
+	}
+	h.probing = true
+	return true
+}
+
+// done records the outcome of a request to the host.
+func (h *host) done(ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.probing = false
+	if ok {
+		h.failures = 0
+		return
+	}
+	h.failures++
+	h.openedAt = time.Now()
+}
+
+// release lets another request probe the host without recording any
+// outcome.
+func (h *host) release() {
+	h.mu.Lock()
+	h.probing = false
+	h.mu.Unlock()
+}
+
+// retryable reports whether a request that got resp or err is worth
+// retrying.
+func retryable(resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// sleep waits for d or until the request context is done.
+func sleep(req *http.Request, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+func drain(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
@@ -0,0 +1,212 @@
+package httppolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransportRetry(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		r.ParseForm()
+		fmt.Fprint(w, r.PostForm.Get("mes"))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Retries:    2,
+		MinBackoff: time.Millisecond,
+	}}
+
+	resp, err := client.Post(ts.URL, "application/x-www-form-urlencoded", strings.NewReader("mes=5"))
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "5" {
+		t.Fatalf("expected 5, got %d %s", resp.StatusCode, body)
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 requests to server, got %d", hits)
+	}
+}
+
+func TestTransportRetryExhausted(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Retries:    2,
+		MinBackoff: time.Millisecond,
+	}}
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway || hits != 3 {
+		t.Fatalf("expected 3 requests ending in %d, got %d ending in %d", http.StatusBadGateway, hits, resp.StatusCode)
+	}
+}
+
+func TestTransportNoRetry(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{Retries: 3}}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	resp.Body.Close()
+
+	if hits != 1 {
+		t.Fatalf("expected 1 request to server, got %d", hits)
+	}
+}
+
+func TestTransportCircuitBreaker(t *testing.T) {
+	var hits, fail int32 = 0, 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Threshold: 2,
+		Cooldown:  50 * time.Millisecond,
+	}}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("expected no error, but got error: %s", err.Error())
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(ts.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected %s, got %v", ErrCircuitOpen, err)
+	}
+	if hits != 2 {
+		t.Fatalf("expected 2 requests to server, got %d", hits)
+	}
+
+	atomic.StoreInt32(&fail, 0)
+	time.Sleep(60 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("expected no error, but got error: %s", err.Error())
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestTransportConcurrency(t *testing.T) {
+	var inFlight, max int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{Concurrency: 2}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(ts.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if max > 2 {
+		t.Fatalf("expected at most 2 requests in flight, got %d", max)
+	}
+}
+
+func TestTransportDelay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	delay := 20 * time.Millisecond
+	client := &http.Client{Transport: &Transport{Delay: delay}}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("expected no error, but got error: %s", err.Error())
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 2*delay {
+		t.Fatalf("expected at least %s between requests, took %s", delay, elapsed)
+	}
+}
+
+func TestTransportContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := &http.Client{Transport: &Transport{
+		Retries:    10,
+		MinBackoff: time.Second,
+	}}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	_, err := client.Do(req.WithContext(ctx))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
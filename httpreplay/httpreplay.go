@@ -0,0 +1,203 @@
+// Package httpreplay records HTTP exchanges into a fixture directory and
+// serves them back, so code talking to a website can be tested offline
+// and deterministically.
+package httpreplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoFixture is returned by Replayer for requests that weren't recorded.
+var ErrNoFixture = errors.New("httpreplay: no fixture recorded")
+
+type (
+	// Fixture is a recorded request and the response it got.
+	Fixture struct {
+		Method     string      `json:"method"`
+		URL        string      `json:"url"`
+		Form       string      `json:"form,omitempty"`
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		// Body is kept as is, encoded in base64, since pages and images
+		// aren't necessarily UTF-8.
+		Body []byte `json:"body"`
+	}
+
+	// Recorder is a http.RoundTripper that makes requests with Base and
+	// saves every exchange as a Fixture in Dir.
+	Recorder struct {
+		// Dir where fixtures are written. It is created if needed.
+		Dir string
+
+		// Base is the transport used to make requests. If nil,
+		// http.DefaultTransport is used.
+		Base http.RoundTripper
+
+		// Key returns the key identifying a request given its body. If
+		// nil, DefaultKey is used.
+		Key func(req *http.Request, body []byte) string
+	}
+
+	// Replayer is a http.RoundTripper that answers requests with the
+	// fixtures saved in Dir, without touching the network.
+	Replayer struct {
+		// Dir where fixtures are read from.
+		Dir string
+
+		// Key returns the key identifying a request given its body. It
+		// must match the one used by the Recorder. If nil, DefaultKey is
+		// used.
+		Key func(req *http.Request, body []byte) string
+	}
+)
+
+var reUnsafe = regexp.MustCompile(`[^a-zA-Z0-9.]+`)
+
+// DefaultKey builds a key from request method, URL and body. Form bodies
+// are sorted by field name so the key doesn't depend on encoding order.
+func DefaultKey(req *http.Request, body []byte) string {
+	key := req.Method + " " + req.URL.String()
+	if len(body) == 0 {
+		return key
+	}
+	if values, err := url.ParseQuery(string(body)); err == nil {
+		body = []byte(values.Encode())
+	}
+	return key + " " + string(body)
+}
+
+// Filename returns the name of the fixture file for the request with the
+// given key. It starts with a readable form of the URL path, so fixture
+// directories are easy to browse, and ends with a hash of the key.
+func Filename(req *http.Request, key string) string {
+	name := strings.Trim(reUnsafe.ReplaceAllString(req.URL.Path, "-"), "-.")
+	if len(name) > 64 {
+		name = name[len(name)-64:]
+	}
+	if name == "" {
+		name = "index"
+	}
+	sum := sha256.Sum256([]byte(key))
+	return name + "-" + hex.EncodeToString(sum[:6]) + ".json"
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	f := &Fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Form:       string(body),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       data,
+	}
+	if err := r.save(Filename(req, key(r.Key, req, body)), f); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) save(name string, f *Fixture) error {
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(r.Dir, name), data, 0644)
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	k := key(r.Key, req, body)
+	data, err := ioutil.ReadFile(filepath.Join(r.Dir, Filename(req, k)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNoFixture, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(f.StatusCode) + " " + http.StatusText(f.StatusCode),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+func key(keyFunc func(*http.Request, []byte) string, req *http.Request, body []byte) string {
+	if keyFunc == nil {
+		keyFunc = DefaultKey
+	}
+	return keyFunc(req, body)
+}
+
+// readBody reads the request body and puts it back so it can be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
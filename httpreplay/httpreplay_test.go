@@ -0,0 +1,110 @@
+package httpreplay
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello " + r.Form.Get("name")))
+	}))
+
+	dir, err := ioutil.TempDir("", "httpreplay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	post := func(rt http.RoundTripper, form string) (*http.Response, error) {
+		client := &http.Client{Transport: rt}
+		return client.Post(ts.URL+"/greet", "application/x-www-form-urlencoded", strings.NewReader(form))
+	}
+
+	resp, err := post(&Recorder{Dir: dir}, "name=mary&lang=en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello mary" {
+		t.Fatalf("expected hello mary, got %s", body)
+	}
+	ts.Close()
+
+	// Same form fields in a different order
+	resp, err = post(&Replayer{Dir: dir}, "lang=en&name=mary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello mary" {
+		t.Fatalf("expected hello mary, got %s", body)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") != `"v1"` {
+		t.Fatalf("expected %s, got %s", `"v1"`, resp.Header.Get("ETag"))
+	}
+
+	_, err = post(&Replayer{Dir: dir}, "name=poppins")
+	if !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("expected %s, got %v", ErrNoFixture, err)
+	}
+}
+
+func TestRecordReplayBinary(t *testing.T) {
+	// Start of a JPEG and "ação" in Latin-1, neither being valid UTF-8
+	data := []byte{0xff, 0xd8, 0xff, 0xe0, 'a', 0xe7, 0xe3, 'o'}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+
+	dir, err := ioutil.TempDir("", "httpreplay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resp, err := (&http.Client{Transport: &Recorder{Dir: dir}}).Get(ts.URL + "/poster.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	ts.Close()
+
+	resp, err = (&http.Client{Transport: &Replayer{Dir: dir}}).Get(ts.URL + "/poster.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(body, data) {
+		t.Fatalf("expected % x, got % x", data, body)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://claquete.com.br/programacao/656/cinema-656.html", nil)
+	name := Filename(req, DefaultKey(req, nil))
+	if !strings.HasPrefix(name, "programacao-656-cinema-656.html-") || !strings.HasSuffix(name, ".json") {
+		t.Fatalf("unexpected filename %s", name)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://claquete.com.br", nil)
+	name = Filename(req, DefaultKey(req, nil))
+	if !strings.HasPrefix(name, "index-") {
+		t.Fatalf("unexpected filename %s", name)
+	}
+}
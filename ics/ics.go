@@ -0,0 +1,317 @@
+// Package ics encodes schedules and release calendars as iCalendar
+// (RFC 5545) documents, so they can be subscribed to in calendar apps.
+//
+// Events have UIDs derived from what identifies them in the website, so
+// importing a newer document updates the events instead of duplicating
+// them.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+)
+
+const (
+	// ProdID identifies the program that created the documents.
+	ProdID = "-//dsbezerra//claqueteapi//PT"
+
+	// domain is the right-hand side of every UID.
+	domain = "claquete.com.br"
+
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+
+	// maxLine is the length in octets lines are folded at.
+	maxLine = 75
+)
+
+var versions = map[string]string{
+	claquete.VersionDubbed:    "Dublado",
+	claquete.VersionNational:  "Nacional",
+	claquete.VersionSubtitled: "Legendado",
+}
+
+// Encoder writes iCalendar documents to an output stream.
+type Encoder struct {
+	w *bufio.Writer
+
+	// Runtimes maps movie IDs to their runtime in minutes, used to set
+	// when sessions without EndTime end. Sessions of movies without one
+	// have no end. See AddRuntimes.
+	Runtimes map[int]int
+
+	// Stamp is the DTSTAMP of the events. If zero, the time of encoding
+	// is used.
+	Stamp time.Time
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// AddRuntimes sets the runtime of the given movies, as retrieved by
+// claquete.GetMovie, in Runtimes.
+func (e *Encoder) AddRuntimes(movies ...claquete.Movie) {
+	if e.Runtimes == nil {
+		e.Runtimes = make(map[int]int)
+	}
+	for _, m := range movies {
+		if m.Runtime > 0 {
+			e.Runtimes[m.ID] = m.Runtime
+		}
+	}
+}
+
+// EncodeSchedule writes the sessions of sched as a calendar named after
+// its cinema, one event per session.
+func (e *Encoder) EncodeSchedule(sched *claquete.Schedule) error {
+	var cinema claquete.Cinema
+	if sched.Cinema != nil {
+		cinema = *sched.Cinema
+	}
+
+	loc := time.UTC
+	if cinema.TimeZone != "" {
+		l, err := time.LoadLocation(cinema.TimeZone)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+
+	stamp := e.stamp()
+	e.begin(cinema.Name)
+	if loc != time.UTC {
+		e.timezone(loc, sched)
+	}
+
+	for _, s := range sched.Sessions {
+		if s.StartTime == nil {
+			continue
+		}
+		start := s.StartTime.In(loc)
+
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", sessionUID(s))
+		e.line("DTSTAMP", stamp)
+		e.dateTime("DTSTART", start)
+		if s.EndTime != nil {
+			e.dateTime("DTEND", s.EndTime.In(loc))
+		} else if runtime := e.Runtimes[s.MovieID]; runtime > 0 {
+			e.dateTime("DTEND", start.Add(time.Duration(runtime)*time.Minute))
+		}
+		e.text("SUMMARY", s.MovieTitle)
+		e.text("LOCATION", location(cinema, s))
+		if c := cinema.Coordinates; c != nil {
+			e.line("GEO", strconv.FormatFloat(c.Latitude, 'f', -1, 64)+";"+strconv.FormatFloat(c.Longitude, 'f', -1, 64))
+		}
+		e.text("DESCRIPTION", description(s))
+		e.line("URL", movieURL(s.MovieID))
+		e.line("END", "VEVENT")
+	}
+
+	e.line("END", "VCALENDAR")
+	return e.w.Flush()
+}
+
+// EncodeCalendar writes the releases of cal as a calendar of all-day
+// events, one per movie in the day of its release.
+func (e *Encoder) EncodeCalendar(cal *claquete.Calendar) error {
+	stamp := e.stamp()
+	e.begin(fmt.Sprintf("Lançamentos %02d/%d", int(cal.Month), cal.Year))
+
+	for _, week := range cal.Weeks {
+		if week.Date.IsZero() {
+			continue
+		}
+		for _, m := range week.Movies {
+			e.line("BEGIN", "VEVENT")
+			e.line("UID", releaseUID(m))
+			e.line("DTSTAMP", stamp)
+			e.line("DTSTART;VALUE=DATE", week.Date.Format(dateFormat))
+			e.line("DTEND;VALUE=DATE", week.Date.AddDate(0, 0, 1).Format(dateFormat))
+			e.line("TRANSP", "TRANSPARENT")
+			e.text("SUMMARY", m.Title)
+			if m.ID > 0 {
+				e.line("URL", movieURL(m.ID))
+			}
+			e.line("END", "VEVENT")
+		}
+	}
+
+	e.line("END", "VCALENDAR")
+	return e.w.Flush()
+}
+
+func (e *Encoder) stamp() string {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	return stamp.UTC().Format(dateTimeFormat) + "Z"
+}
+
+func (e *Encoder) begin(name string) {
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if name != "" {
+		e.text("X-WR-CALNAME", name)
+	}
+}
+
+// timezone writes the definition of loc with its offset at the start of
+// the schedule. Brazilian time zones have no daylight saving time since
+// 2019, so a single standard observance describes them.
+func (e *Encoder) timezone(loc *time.Location, sched *claquete.Schedule) {
+	at := time.Now()
+	if sched.Period != nil {
+		at = sched.Period.Start
+	}
+	name, offset := at.In(loc).Zone()
+
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", loc.String())
+	e.line("BEGIN", "STANDARD")
+	e.line("DTSTART", "19700101T000000")
+	e.line("TZOFFSETFROM", formatOffset(offset))
+	e.line("TZOFFSETTO", formatOffset(offset))
+	e.text("TZNAME", name)
+	e.line("END", "STANDARD")
+	e.line("END", "VTIMEZONE")
+	e.text("X-WR-TIMEZONE", loc.String())
+}
+
+// dateTime writes a date-time property in the time zone of t.
+func (e *Encoder) dateTime(name string, t time.Time) {
+	if t.Location() == time.UTC {
+		e.line(name, t.Format(dateTimeFormat)+"Z")
+		return
+	}
+	e.line(name+";TZID="+t.Location().String(), t.Format(dateTimeFormat))
+}
+
+// text writes a property with a text value, escaping it.
+func (e *Encoder) text(name, value string) {
+	e.line(name, escape(value))
+}
+
+// line writes a content line, folding it at 75 octets without splitting
+// UTF-8 sequences.
+func (e *Encoder) line(name, value string) {
+	l := name + ":" + value
+	n := 0
+	for len(l) > 0 {
+		limit := maxLine
+		if n > 0 {
+			// Continuation lines start with a space.
+			limit--
+			e.w.WriteString("\r\n ")
+		}
+		if len(l) <= limit {
+			e.w.WriteString(l)
+			break
+		}
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(l[cut]) {
+			cut--
+		}
+		e.w.WriteString(l[:cut])
+		l = l[cut:]
+		n++
+	}
+	e.w.WriteString("\r\n")
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+// sessionUID identifies a session by its ID, see claquete.Session.ID.
+func sessionUID(s claquete.Session) string {
+	return s.ID() + "@" + domain
+}
+
+// releaseUID identifies a release by its movie only, so a postponed
+// release moves its event.
+func releaseUID(m claquete.Movie) string {
+	id := strconv.Itoa(m.ID)
+	if m.ID == 0 {
+		id = m.Slug
+		if id == "" {
+			id = strings.ToLower(strings.Join(strings.Fields(m.Title), "-"))
+		}
+	}
+	return "release-" + id + "@" + domain
+}
+
+func location(cinema claquete.Cinema, s claquete.Session) string {
+	var parts []string
+	if cinema.Name != "" {
+		parts = append(parts, cinema.Name)
+	}
+	if room := s.Room.String(); room != "" {
+		parts = append(parts, room)
+	}
+	if cinema.AddressLine != "" {
+		parts = append(parts, cinema.AddressLine)
+	}
+	return strings.Join(parts, " - ")
+}
+
+func description(s claquete.Session) string {
+	var lines []string
+	if room := s.Room.String(); room != "" {
+		lines = append(lines, room)
+	}
+	if s.Format != "" {
+		lines = append(lines, "Formato: "+s.Format)
+	}
+	if v, ok := versions[s.Version]; ok {
+		lines = append(lines, "Versão: "+v)
+	}
+	var flags, accessibility []string
+	for _, f := range s.Features {
+		if f.Accessibility() {
+			accessibility = append(accessibility, f.String())
+		} else {
+			flags = append(flags, f.String())
+		}
+	}
+	if len(flags) > 0 {
+		lines = append(lines, "Sala especial: "+strings.Join(flags, ", "))
+	}
+	if len(accessibility) > 0 {
+		lines = append(lines, "Acessibilidade: "+strings.Join(accessibility, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func movieURL(id int) string {
+	return claquete.BaseURL + "/filmes/filme.php?cf=" + strconv.Itoa(id)
+}
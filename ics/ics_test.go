@@ -0,0 +1,152 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	claquete "github.com/dsbezerra/claqueteapi"
+)
+
+// unfold joins folded lines back and splits the document in lines.
+func unfold(doc string) []string {
+	return strings.Split(strings.TrimSuffix(strings.Replace(doc, "\r\n ", "", -1), "\r\n"), "\r\n")
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEncodeSchedule(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	start := time.Date(2019, time.May, 23, 14, 0, 0, 0, loc)
+	sched := &claquete.Schedule{
+		Cinema: &claquete.Cinema{
+			ID:          656,
+			Name:        "Cinemais Montes Claros",
+			AddressLine: "Av. Donato Quintino, 90 - Cidade Nova, Montes Claros - MG",
+			TimeZone:    "America/Sao_Paulo",
+		},
+		Period: &claquete.Period{Start: start, End: start.AddDate(0, 0, 6)},
+		Sessions: []claquete.Session{
+			{
+				CinemaID:   656,
+				MovieID:    8427,
+				MovieTitle: "O Retorno de Mary Poppins",
+				Format:     claquete.Format3D,
+				Version:    claquete.VersionDubbed,
+				StartTime:  &start,
+				Room:       claquete.Room{Label: "Sala 1", Number: 1},
+				Features:   claquete.Features{claquete.FeatureVIP},
+			},
+			{
+				CinemaID:   656,
+				MovieID:    8600,
+				MovieTitle: "Vingadores: Ultimato",
+				Format:     claquete.Format2D,
+				Version:    claquete.VersionSubtitled,
+				StartTime:  &start,
+				Room:       claquete.Room{Label: "Sala 2", Number: 2},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Stamp = time.Date(2019, time.May, 22, 12, 0, 0, 0, time.UTC)
+	enc.AddRuntimes(claquete.Movie{ID: 8427, Runtime: 130})
+	if err := enc.EncodeSchedule(sched); err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	for _, l := range strings.Split(buf.String(), "\r\n") {
+		if len(l) > maxLine {
+			t.Fatalf("expected lines of at most %d octets, got %d: %s", maxLine, len(l), l)
+		}
+	}
+
+	lines := unfold(buf.String())
+	expected := []string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:Cinemais Montes Claros",
+		"TZID:America/Sao_Paulo",
+		"TZOFFSETTO:-0300",
+		"UID:" + sched.Sessions[0].ID() + "@claquete.com.br",
+		"DTSTAMP:20190522T120000Z",
+		"DTSTART;TZID=America/Sao_Paulo:20190523T140000",
+		"DTEND;TZID=America/Sao_Paulo:20190523T161000",
+		"SUMMARY:O Retorno de Mary Poppins",
+		`LOCATION:Cinemais Montes Claros - Sala 1 - Av. Donato Quintino\, 90 - Cidade Nova\, Montes Claros - MG`,
+		`DESCRIPTION:Sala 1\nFormato: 3D\nVersão: Dublado\nSala especial: VIP`,
+		"URL:http://claquete.com.br/filmes/filme.php?cf=8427",
+		"UID:" + sched.Sessions[1].ID() + "@claquete.com.br",
+		"END:VCALENDAR",
+	}
+	for _, l := range expected {
+		if !contains(lines, l) {
+			t.Fatalf("expected line %q in\n%s", l, buf.String())
+		}
+	}
+
+	if n := strings.Count(buf.String(), "DTEND"); n != 1 {
+		t.Fatalf("expected DTEND only for the movie with runtime, got %d", n)
+	}
+}
+
+func TestEncodeCalendar(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	cal := &claquete.Calendar{
+		Month: time.May,
+		Year:  2019,
+		Weeks: []claquete.ReleaseWeek{{
+			Date: time.Date(2019, time.May, 30, 0, 0, 0, 0, loc),
+			Movies: []claquete.Movie{
+				{ID: 8600, Title: "Rocketman"},
+				{Title: "Sem Link"},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).EncodeCalendar(cal); err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	lines := unfold(buf.String())
+	expected := []string{
+		"X-WR-CALNAME:Lançamentos 05/2019",
+		"UID:release-8600@claquete.com.br",
+		"DTSTART;VALUE=DATE:20190530",
+		"DTEND;VALUE=DATE:20190531",
+		"SUMMARY:Rocketman",
+		"UID:release-sem-link@claquete.com.br",
+	}
+	for _, l := range expected {
+		if !contains(lines, l) {
+			t.Fatalf("expected line %q in\n%s", l, buf.String())
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	value := strings.Repeat("ção", 40)
+	enc.text("SUMMARY", value)
+	enc.w.Flush()
+
+	for _, l := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(l) > maxLine {
+			t.Fatalf("expected at most %d octets, got %d", maxLine, len(l))
+		}
+	}
+	if lines := unfold(buf.String()); lines[0] != "SUMMARY:"+value {
+		t.Fatalf("expected %s, got %s", value, lines[0])
+	}
+}
@@ -1,6 +1,7 @@
 package claquete
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gocolly/colly"
@@ -122,12 +123,31 @@ type (
 )
 
 // GetStates retrieves list of states.
-func GetStates() ([]State, error) {
+func GetStates(options ...Options) ([]State, error) {
+	return GetStatesContext(context.Background(), options...)
+}
+
+// GetStatesContext is like GetStates but aborts when ctx is done.
+func GetStatesContext(ctx context.Context, options ...Options) ([]State, error) {
+	return NewClaquete(options...).GetStatesContext(ctx)
+}
+
+// GetStates retrieves list of states.
+func (c *Claquete) GetStates() ([]State, error) {
+	return c.GetStatesContext(context.Background())
+}
+
+// GetStatesContext is like GetStates but aborts when ctx is done.
+func (c *Claquete) GetStatesContext(ctx context.Context) ([]State, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var result []State
 	var err error
 
-	c := NewClaquete()
-	c.collector.OnHTML("#selUf > option", func(e *colly.HTMLElement) {
+	collector := c.collect(ctx)
+	collector.OnHTML(selStates, func(e *colly.HTMLElement) {
 		value := e.Attr("value")
 		if isFederativeUnitValid(value) {
 			result = append(result, State{
@@ -137,42 +157,51 @@ func GetStates() ([]State, error) {
 			})
 		}
 	})
-	err = c.collector.Visit(BaseURL)
+	err = collector.Visit(c.url(""))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	return result, err
 }
 
 // GetCities retrieve list of cities.
 func (s *State) GetCities() ([]City, error) {
+	return s.GetCitiesContext(context.Background())
+}
+
+// GetCitiesContext is like GetCities but aborts when ctx is done.
+func (s *State) GetCitiesContext(ctx context.Context) ([]City, error) {
 	var result []City
-	var err error
 
-	gotCookies := false
-	s.c.collector.AllowURLRevisit = true
-	s.c.collector.OnResponse(func(r *colly.Response) {
-		// Now with cookies we visit the index page
-		// to get the list of cities.
-		if !gotCookies {
-			gotCookies = true
-			s.c.collector.Visit(BaseURL)
-		}
-	})
+	c := clientOrDefault(s.c)
+	if c.err != nil {
+		return nil, c.err
+	}
+	// The index page lists the cities of the selected state
+	collector, err := c.collectState(ctx, s.FU)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	s.c.collector.OnHTML("#cidade > option", func(e *colly.HTMLElement) {
+	collector.OnHTML(selCities, func(e *colly.HTMLElement) {
 		value := e.Attr("value")
 		if value != "0" {
 			result = append(result, City{
-				c:    s.c,
-				Name: e.Text,
+				c:     c,
+				State: State{FU: s.FU, Name: s.Name},
+				Name:  e.Text,
 			})
 		}
 	})
 
-	// POST will set cookies.
-	err = s.c.collector.Post(BaseAJAX+"escolherEstados.php", map[string]string{
-		"UF": string(s.FU),
-	})
+	err = collector.Visit(c.url(""))
 
-	s.c.collector.AllowURLRevisit = false
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 
 	return result, err
 }
@@ -181,21 +210,36 @@ func (s *State) GetCities() ([]City, error) {
 // To get additional movie metadata use the GetMovie(int)
 // function passing the retrieved movie id.
 func (c *City) GetNowPlaying() ([]Movie, error) {
+	return c.GetNowPlayingContext(context.Background())
+}
+
+// GetNowPlayingContext is like GetNowPlaying but aborts when ctx is done.
+func (c *City) GetNowPlayingContext(ctx context.Context) ([]Movie, error) {
 	params := map[string]string{"cidade": c.Name}
-	return getNowPlayingList(c.c.collector, "escolherFilme_cidade.php", params)
+	return clientOrDefault(c.c).getNowPlayingList(ctx, c.State.FU, "escolherFilme_cidade.php", params)
 }
 
 // GetCities for current claquete's federative unit
 func (c *Claquete) GetCities() ([]City, error) {
+	return c.GetCitiesContext(context.Background())
+}
+
+// GetCitiesContext is like GetCities but aborts when ctx is done.
+func (c *Claquete) GetCitiesContext(ctx context.Context) ([]City, error) {
 	s := &State{c: c, FU: c.fu, Name: getStateName(c.fu)}
-	return s.GetCities()
+	return s.GetCitiesContext(ctx)
 }
 
 // GetCities for specific Federative Unit independent of
 // Claquete instance
-func GetCities(fu string) ([]City, error) {
-	c := NewClaquete(FederativeUnit(fu))
-	return c.GetCities()
+func GetCities(fu string, options ...Options) ([]City, error) {
+	return GetCitiesContext(context.Background(), fu, options...)
+}
+
+// GetCitiesContext is like GetCities but aborts when ctx is done.
+func GetCitiesContext(ctx context.Context, fu string, options ...Options) ([]City, error) {
+	c := NewClaquete(append([]Options{FederativeUnit(fu)}, options...)...)
+	return c.GetCitiesContext(ctx)
 }
 
 func getStateName(s string) string {
@@ -265,10 +309,8 @@ func getTimeZone(s string) string {
 		result = "America/Rio_Branco"
 	} else if strings.EqualFold(s, Alagoas) || strings.EqualFold(s, Sergipe) {
 		result = "America/Maceio"
-		// NOTE: or America/Santarem for west of Para, refactor if necessary
 	} else if strings.EqualFold(s, Amapa) || strings.EqualFold(s, Para) {
 		result = "America/Belem"
-		// NOTE: or America/Eirunepe for west, refactor if necessary
 	} else if strings.EqualFold(s, Amazonas) {
 		result = "America/Manaus"
 	} else if strings.EqualFold(s, Bahia) {
@@ -5,7 +5,7 @@ import (
 )
 
 func TestGetStates(t *testing.T) {
-	states, err := GetStates()
+	states, err := GetStates(fixtures(t))
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -17,14 +17,14 @@ func TestGetStates(t *testing.T) {
 }
 
 func TestGetCities(t *testing.T) {
-	_, err := GetCities(AC)
+	_, err := GetCities(AC, fixtures(t))
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
 }
 
 func TestGetCitiesFromState(t *testing.T) {
-	states, err := GetStates()
+	states, err := GetStates(fixtures(t))
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -1,7 +1,10 @@
 package claquete
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -34,10 +37,6 @@ const (
 	ImageTypePoster = "poster"
 )
 
-var (
-	errMovieNotFound = errors.New("movie not found")
-)
-
 type (
 
 	// Movie represents a movie in Claquete's website
@@ -60,6 +59,9 @@ type (
 		Rating        int        `json:"rating,omitempty"`
 		ReleaseDate   *time.Time `json:"release_date,omitempty"`
 		Images        []Image    `json:"images,omitempty"`
+		// Diagnostics lists the fields that couldn't be parsed and the
+		// images that couldn't be probed.
+		Diagnostics ParseErrors `json:"diagnostics,omitempty"`
 	}
 
 	// Image TODO
@@ -74,9 +76,30 @@ type (
 )
 
 // GetMovie retrieves movie information for the given ID
-func GetMovie(id int) (*Movie, error) {
+func GetMovie(id int, options ...Options) (*Movie, error) {
+	return GetMovieContext(context.Background(), id, options...)
+}
+
+// GetMovieContext is like GetMovie but aborts when ctx is done, including
+// any image probe still in flight.
+func GetMovieContext(ctx context.Context, id int, options ...Options) (*Movie, error) {
+	return NewClaquete(options...).GetMovieContext(ctx, id)
+}
+
+// GetMovie retrieves movie information for the given ID
+func (c *Claquete) GetMovie(id int) (*Movie, error) {
+	return c.GetMovieContext(context.Background(), id)
+}
+
+// GetMovieContext is like GetMovie but aborts when ctx is done, including
+// any image probe still in flight.
+func (c *Claquete) GetMovieContext(ctx context.Context, id int) (*Movie, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	if id < 0 {
-		return nil, errors.New("invalid ID")
+		return nil, errors.Wrapf(ErrInvalidArgument, "movie ID %d", id)
 	}
 
 	idStr := strconv.Itoa(id)
@@ -87,52 +110,82 @@ func GetMovie(id int) (*Movie, error) {
 	var result *Movie
 	var err error
 
-	c := NewClaquete()
-	c.collector.OnResponse(func(r *colly.Response) {
-		// Make sure we got a movie page
-		ID, err := movieutil.IDFromURL(r.Request.URL)
-		if err != nil {
-			fmt.Println(err)
-			c.collector.OnHTMLDetach("div.mvposter")
-			c.collector.OnHTMLDetach("div.mvposter > div.mvclassif img")
-			c.collector.OnHTMLDetach("div.mvdesc")
-			c.collector.OnHTMLDetach("#cont1")
-			c.collector.OnHTMLDetach("#cont2 > galeria img")
-		} else {
-			result = &Movie{
-				ID:   ID,
-				Page: r.Request.URL.String(),
-			}
-		}
+	collector := c.collect(ctx)
+	collector.OnResponse(func(r *colly.Response) {
+		result, err = ParseMovie(bytes.NewReader(r.Body), r.Request.URL.String())
 	})
 
-	c.collector.OnHTML("div.mvposter", func(e *colly.HTMLElement) {
-		result.Poster = e.Attr("src")
-	})
+	errVisit := collector.Visit(c.url("/filmes/filme.php?cf=" + idStr))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errVisit != nil {
+		return nil, errVisit
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	c.collector.OnHTML("div.mvposter > div.mvclassif img", func(e *colly.HTMLElement) {
-		src := e.Attr("src")
-		if src != "" {
-			if strings.Contains(src, RatingL) {
-				result.Rating = -1
-			} else if strings.Contains(src, Rating10) {
-				result.Rating = 10
-			} else if strings.Contains(src, Rating12) {
-				result.Rating = 12
-			} else if strings.Contains(src, Rating14) {
-				result.Rating = 14
-			} else if strings.Contains(src, Rating16) {
-				result.Rating = 16
-			} else if strings.Contains(src, Rating18) {
-				result.Rating = 18
-			}
+	c.probeImages(ctx, result)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return result, nil
+}
+
+// ParseMovie parses a movie page read from r. pageURL is the address the
+// page was retrieved from and identifies the movie.
+//
+// Images are returned with their URL only, see GetMovie to have their
+// dimensions and format filled.
+//
+// A page that isn't a movie results in ErrNotFound. Fields that couldn't
+// be parsed are left empty and reported in the movie Diagnostics.
+func ParseMovie(r io.Reader, pageURL string) (*Movie, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseMovie(doc.Selection, pageURL)
+}
+
+func parseMovie(doc *goquery.Selection, pageURL string) (*Movie, error) {
+	// Make sure we got a movie page
+	ID, err := movieutil.IDFromURLString(pageURL)
+	if err != nil {
+		return nil, errors.Wrapf(ErrNotFound, "movie %s", pageURL)
+	}
+
+	result := &Movie{
+		ID:   ID,
+		Page: pageURL,
+	}
+
+	result.Poster = doc.Find("div.mvposter").AttrOr("src", "")
+
+	src := doc.Find(selMovieRating).AttrOr("src", "")
+	if src != "" {
+		if strings.Contains(src, RatingL) {
+			result.Rating = -1
+		} else if strings.Contains(src, Rating10) {
+			result.Rating = 10
+		} else if strings.Contains(src, Rating12) {
+			result.Rating = 12
+		} else if strings.Contains(src, Rating14) {
+			result.Rating = 14
+		} else if strings.Contains(src, Rating16) {
+			result.Rating = 16
+		} else if strings.Contains(src, Rating18) {
+			result.Rating = 18
 		}
-	})
+	}
 
-	c.collector.OnHTML("div.mvdesc", func(e *colly.HTMLElement) {
-		result.Title = strings.TrimSpace(e.DOM.Find("h1").Text())
+	desc := doc.Find(selMovieDesc)
+	if desc.Length() != 0 {
+		result.Title = strings.TrimSpace(desc.Find(selMovieTitle).Text())
 		result.Slug = util.CreateSlug(result.Title)
-		ot := strings.TrimSpace(e.DOM.Find("h2").Text())
+		ot := strings.TrimSpace(desc.Find(selMovieOrig).Text())
 		if ot != "" {
 			// Skip parentheses and year
 			// Ex: (Original Title, Year)
@@ -146,7 +199,7 @@ func GetMovie(id int) (*Movie, error) {
 			}
 		}
 
-		e.DOM.Find("p").Each(func(i int, s *goquery.Selection) {
+		desc.Find("p").Each(func(i int, s *goquery.Selection) {
 			label, value := util.BreakByToken(s.Text(), ':')
 			label = strings.ToLower(label)
 			if label != "" && value != "" {
@@ -160,7 +213,7 @@ func GetMovie(id int) (*Movie, error) {
 				case "duração":
 					runtime, err := movieutil.ParseRuntime(value)
 					if err != nil {
-						fmt.Println(err)
+						result.Diagnostics.add(pageURL, selMovieDesc+" "+selMovieInfo, value, err)
 					} else {
 						result.Runtime = runtime
 					}
@@ -173,103 +226,131 @@ func GetMovie(id int) (*Movie, error) {
 					// typo or cut and paste from distr.
 					rd, err := movieutil.ParseReleaseDate(value, "/")
 					if err != nil {
-						fmt.Println(err)
+						result.Diagnostics.add(pageURL, selMovieDesc+" "+selMovieInfo, value, err)
 					} else {
 						result.ReleaseDate = rd
 					}
 				}
 			}
 		})
-	})
+	}
 
-	c.collector.OnHTML("#cont1", func(e *colly.HTMLElement) {
-		getSplitted := func(str string) []string {
-			str = strings.TrimFunc(str, func(r rune) bool {
-				return unicode.IsSpace(r) || r == ','
-			})
-			str = strings.Replace(str, "\n", " ", -1)
-			return strings.Split(str, ", ")
-		}
-		e.DOM.Children().Each(func(i int, s *goquery.Selection) {
-			if s.Is("h3") {
-				header := strings.TrimSpace(strings.ToLower(s.Text()))
-				value := strings.TrimSpace(s.Next().Text())
-				switch header {
-				case "sinopse":
-					result.Synopsis = value
-				case "elenco":
-					result.Cast = getSplitted(value)
-				case "roteiro":
-					result.Screenplay = getSplitted(value)
-				case "produção":
-					result.Production = getSplitted(value)
-				case "direção":
-					result.Direction = getSplitted(value)
-				}
-			}
+	getSplitted := func(str string) []string {
+		str = strings.TrimFunc(str, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ','
 		})
-	})
-
-	c.collector.OnHTML("#cont2", func(e *colly.HTMLElement) {
-		e.DOM.Find("img").Each(func(i int, s *goquery.Selection) {
-			src := s.AttrOr("src", "")
-			if src != "" {
-				image, err := util.GetImage(src)
-				if err != nil {
-					fmt.Printf("error %s ocurred while getting image from %s\n", err.Error(), src)
-				} else {
-					i := Image{
-						URL:        src,
-						Width:      image.Width,
-						Height:     image.Height,
-						Resolution: image.Resolution,
-						Format:     image.Format,
-					}
-					// Likely to be a poster image.
-					if image.Height > image.Width {
-						i.Type = "poster"
-					} else {
-						i.Type = ImageTypeAny
-					}
-					result.Images = append(result.Images, i)
-				}
+		str = strings.Replace(str, "\n", " ", -1)
+		return strings.Split(str, ", ")
+	}
+	doc.Find(selMovieCast).Children().Each(func(i int, s *goquery.Selection) {
+		if s.Is("h3") {
+			header := strings.TrimSpace(strings.ToLower(s.Text()))
+			value := strings.TrimSpace(s.Next().Text())
+			switch header {
+			case "sinopse":
+				result.Synopsis = value
+			case "elenco":
+				result.Cast = getSplitted(value)
+			case "roteiro":
+				result.Screenplay = getSplitted(value)
+			case "produção":
+				result.Production = getSplitted(value)
+			case "direção":
+				result.Direction = getSplitted(value)
 			}
-		})
+		}
 	})
 
-	err = c.collector.Visit(BaseURL + "/filmes/filme.php?cf=" + idStr)
+	doc.Find(selMovieImages).Each(func(i int, s *goquery.Selection) {
+		src := s.AttrOr("src", "")
+		if src != "" {
+			result.Images = append(result.Images, Image{
+				URL:  resolveURL(pageURL, src),
+				Type: ImageTypeAny,
+			})
+		}
+	})
 
 	// Ignore movies without title.
 	if isMovieInvalid(result) {
-		err = errMovieNotFound
-		result = nil
+		return nil, errors.Wrapf(ErrNotFound, "movie %d", ID)
 	}
 
-	return result, err
+	return result, nil
+}
+
+// probeImages fills dimensions and format of the movie images, dropping
+// the ones that couldn't be retrieved and reporting them in Diagnostics.
+func (c *Claquete) probeImages(ctx context.Context, m *Movie) {
+	var images []Image
+	for _, i := range m.Images {
+		if ctx.Err() != nil {
+			return
+		}
+		image, err := util.GetImageWithClient(ctx, c.httpClient(ctx), i.URL)
+		if err != nil {
+			m.Diagnostics.add(i.URL, selMovieImages, "", err)
+			continue
+		}
+		i.Width = image.Width
+		i.Height = image.Height
+		i.Resolution = image.Resolution
+		i.Format = image.Format
+		// Likely to be a poster image.
+		if image.Height > image.Width {
+			i.Type = ImageTypePoster
+		} else {
+			i.Type = ImageTypeAny
+		}
+		images = append(images, i)
+	}
+	m.Images = images
 }
 
 // getNowPlayingList is a helper to retrieve list of
-// movies inside a select element
-func getNowPlayingList(c *colly.Collector, path string, params map[string]string) ([]Movie, error) {
+// movies inside a select element, for the federative
+// unit fu when not empty.
+func (c *Claquete) getNowPlayingList(ctx context.Context, fu, path string, params map[string]string) ([]Movie, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var result []Movie
-	var err error
-	c.OnHTML("option", func(e *colly.HTMLElement) {
+	var errs ParseErrors
+	collector, err := c.collectState(ctx, fu)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	collector.OnHTML("option", func(e *colly.HTMLElement) {
 		value := e.Attr("value")
+		if value == "" {
+			return
+		}
 		ID, err := strconv.Atoi(value)
 		if err != nil {
-			fmt.Println(errors.Wrapf(err, "conversion value %s to integer failed", value))
+			errs.add(e.Request.URL.String(), "option", value, err)
 			return
 		}
 		if ID != 0 {
 			result = append(result, Movie{
 				ID:    ID,
 				Title: e.Text,
-				Page:  fmt.Sprintf("%s/filmes/filme.php?cf=%d", BaseURL, ID),
+				Page:  c.url(fmt.Sprintf("/filmes/filme.php?cf=%d", ID)),
 			})
 		}
 	})
-	err = c.Post(BaseAJAX+path, params)
-	return result, err
+	err = collector.Post(c.ajax(path), params)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.report(errs)
+	return result, nil
 }
 
 func isMovieInvalid(m *Movie) bool {
@@ -1,18 +1,20 @@
 package claquete
 
 import (
+	"errors"
+	"os"
 	"strings"
 	"testing"
 	"time"
 )
 
 func TestGetMovie(t *testing.T) {
-	movie, err := GetMovie(0)
-	if err != errMovieNotFound {
-		t.Fatalf("Expected not found, but got %+v", movie)
+	movie, err := GetMovie(0, fixtures(t))
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, but got %v", err)
 	}
 
-	movie, err = GetMovie(8427)
+	movie, err = GetMovie(8427, fixtures(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -78,3 +80,61 @@ func TestGetMovie(t *testing.T) {
 		t.Fatalf("expected %s, got %s", expectedTime, movie.ReleaseDate)
 	}
 }
+
+func TestParseMovie(t *testing.T) {
+	f, err := os.Open("testdata/filme-8427.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	movie, err := ParseMovie(f, "http://claquete.com.br/filmes/filme.php?cf=8427")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if movie.ID != 8427 {
+		t.Fatalf("expected %d, got %d", 8427, movie.ID)
+	}
+
+	expectedTitle := "O Retorno de Mary Poppins"
+	if movie.Title != expectedTitle {
+		t.Fatalf("expected %s, got %s", expectedTitle, movie.Title)
+	}
+
+	expectedOriginalTitle := "Mary Poppins Returns"
+	if movie.OriginalTitle != expectedOriginalTitle {
+		t.Fatalf("expected %s, got %s", expectedOriginalTitle, movie.OriginalTitle)
+	}
+
+	if movie.Rating != -1 {
+		t.Fatalf("expected %d, got %d", -1, movie.Rating)
+	}
+
+	if movie.Runtime != 130 {
+		t.Fatalf("expected %d, got %d", 130, movie.Runtime)
+	}
+
+	expectedCast := "Emily Blunt, Meryl Streep, Colin Firth, Julie Walters, Ben Whishaw"
+	if strings.Join(movie.Cast, ", ") != expectedCast {
+		t.Fatalf("expected %s, got %s", expectedCast, movie.Cast)
+	}
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	expectedTime := time.Date(2018, time.December, 20, 0, 0, 0, 0, loc)
+	if movie.ReleaseDate == nil || expectedTime.Sub(*movie.ReleaseDate) != 0 {
+		t.Fatalf("expected %s, got %s", expectedTime, movie.ReleaseDate)
+	}
+
+	expectedImage := "http://claquete.com.br/fotos/filmes/poster/8427_medio.jpg"
+	if len(movie.Images) != 2 || movie.Images[0].URL != expectedImage {
+		t.Fatalf("expected 2 images starting with %s, got %+v", expectedImage, movie.Images)
+	}
+}
+
+func TestParseMovieNotFound(t *testing.T) {
+	_, err := ParseMovie(strings.NewReader("<html><body></body></html>"), "http://claquete.com.br/filmes/filme.php?cf=0")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, but got %v", err)
+	}
+}
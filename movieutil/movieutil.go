@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dsbezerra/claqueteapi/util"
 	"github.com/pkg/errors"
 )
 
@@ -112,7 +113,7 @@ func ParseReleaseDate(str, sep string) (*time.Time, error) {
 		m, _ := strconv.Atoi(parts[1])
 		y, _ := strconv.Atoi(parts[2])
 		if d != 0 && m != 0 && y != 0 {
-			loc, _ := time.LoadLocation("America/Sao_Paulo")
+			loc := util.LoadLocation("America/Sao_Paulo")
 			result = time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc)
 		} else {
 			err = fmt.Errorf("couldn't convert text %s to date", str)
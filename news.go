@@ -1,7 +1,10 @@
 package claquete
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 type (
 	// Headline struct
 	Headline struct {
+		c        *Claquete
 		Title    string     `json:"title"`
 		Category string     `json:"category,omitempty"`
 		Date     *time.Time `json:"date,omitempty,omitempty"`
@@ -27,21 +31,81 @@ type (
 		Page     string   `json:"page"`
 		Content  string   `json:"content"`
 		HTML     string   `json:"html"`
+		// Diagnostics lists the fields that couldn't be found or parsed.
+		Diagnostics ParseErrors `json:"diagnostics,omitempty"`
 	}
 )
 
 // GetHeadlines ...
-func GetHeadlines() ([]Headline, error) {
+func GetHeadlines(options ...Options) ([]Headline, error) {
+	return GetHeadlinesContext(context.Background(), options...)
+}
+
+// GetHeadlinesContext is like GetHeadlines but aborts when ctx is done.
+func GetHeadlinesContext(ctx context.Context, options ...Options) ([]Headline, error) {
+	return NewClaquete(options...).GetHeadlinesContext(ctx)
+}
+
+// GetHeadlines ...
+func (c *Claquete) GetHeadlines() ([]Headline, error) {
+	return c.GetHeadlinesContext(context.Background())
+}
+
+// GetHeadlinesContext is like GetHeadlines but aborts when ctx is done.
+func (c *Claquete) GetHeadlinesContext(ctx context.Context) ([]Headline, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var result []Headline
+	var errs ParseErrors
 	var err error
 
-	c := NewClaquete()
-	c.collector.OnHTML("body > div.conteudo > div.noticias", func(e *colly.HTMLElement) {
+	collector := c.collect(ctx)
+	collector.OnResponse(func(r *colly.Response) {
+		result, errs, err = parseHeadlines(bytes.NewReader(r.Body), r.Request.URL.String())
+		for i := range result {
+			result[i].c = c
+		}
+	})
+	errVisit := collector.Visit(c.url("/noticias.html"))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errVisit != nil {
+		return nil, errVisit
+	}
+	c.report(errs)
+	return result, err
+}
+
+// ParseHeadlines parses the headlines of a news listing page read from r.
+// pageURL is the address the page was retrieved from and is used to
+// resolve relative links.
+//
+// Headlines whose date is missing or couldn't be parsed are skipped.
+func ParseHeadlines(r io.Reader, pageURL string) ([]Headline, error) {
+	result, _, err := parseHeadlines(r, pageURL)
+	return result, err
+}
+
+// parseHeadlines is like ParseHeadlines but also returns the skipped
+// headlines.
+func parseHeadlines(r io.Reader, pageURL string) ([]Headline, ParseErrors, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result []Headline
+	var errs ParseErrors
+
+	doc.Find(selNews).Each(func(i int, n *goquery.Selection) {
 		h := Headline{}
-		e.DOM.Children().Each(func(i int, s *goquery.Selection) {
+		n.Children().Each(func(i int, s *goquery.Selection) {
 			// Only in highlight
 			if s.Is("a") {
-				p := s.Find("a > div.principal")
+				p := s.Find(selHeadlineImage)
 				if p.Length() != 0 {
 					// Parse first news
 					h.Image = util.GetImageSrc(p)
@@ -51,106 +115,164 @@ func GetHeadlines() ([]Headline, error) {
 				h = Headline{
 					Category: util.GetText("div.subn", s),
 				}
-			} else if s.Is("span") {
-				d, _, err := util.CreateDate(util.GetText("", s), " de ")
+			} else if s.Is(selHeadlineDate) {
+				text := util.GetText("", s)
+				d, _, err := util.CreateDate(text, " de ")
 				if err != nil {
-					fmt.Println(err)
+					errs.add(pageURL, selNews+" > "+selHeadlineDate, text, err)
 				}
 				h.Date = &d
-			} else if s.Is("h2") || s.Is("h1") {
+			} else if s.Is(selHeadlineTitle) || s.Is("h1") {
 				h.Title = util.GetText("", s)
 				h.NewsPage = s.Find("a").AttrOr("href", "")
+				if h.NewsPage != "" {
+					h.NewsPage = resolveURL(pageURL, h.NewsPage)
+				}
+				if h.Date == nil {
+					errs.add(pageURL, selNews+" > "+selHeadlineDate, h.Title, ErrLayoutChanged)
+				}
 			}
 
 			if h.Title != "" && h.NewsPage != "" &&
-				!h.Date.IsZero() {
+				h.Date != nil && !h.Date.IsZero() {
 				result = append(result, h)
 			}
 		})
 	})
-	err = c.collector.Visit(BaseURL + "/noticias.html")
-	return result, err
+
+	return result, errs, nil
 }
 
 // GetNews TODO
 func (h *Headline) GetNews() (*News, error) {
+	return h.GetNewsContext(context.Background())
+}
+
+// GetNewsContext is like GetNews but aborts when ctx is done.
+func (h *Headline) GetNewsContext(ctx context.Context) (*News, error) {
 	if h.NewsPage == "" {
-		return nil, errors.New("missing news page link")
+		return nil, errors.Wrap(ErrInvalidArgument, "missing news page link")
 	}
-	return getNews(h.NewsPage)
+	return clientOrDefault(h.c).getNews(ctx, h.NewsPage)
 }
 
 // GetNewsByID TODO
-func GetNewsByID(id int) (*News, error) {
-	url := fmt.Sprintf("%s/noticia/%d/noticia.html", BaseURL, id)
-	return getNews(url)
+func GetNewsByID(id int, options ...Options) (*News, error) {
+	return GetNewsByIDContext(context.Background(), id, options...)
+}
+
+// GetNewsByIDContext is like GetNewsByID but aborts when ctx is done.
+func GetNewsByIDContext(ctx context.Context, id int, options ...Options) (*News, error) {
+	return NewClaquete(options...).GetNewsByIDContext(ctx, id)
+}
+
+// GetNewsByID TODO
+func (c *Claquete) GetNewsByID(id int) (*News, error) {
+	return c.GetNewsByIDContext(context.Background(), id)
+}
+
+// GetNewsByIDContext is like GetNewsByID but aborts when ctx is done.
+func (c *Claquete) GetNewsByIDContext(ctx context.Context, id int) (*News, error) {
+	url := c.url(fmt.Sprintf("/noticia/%d/noticia.html", id))
+	return c.getNews(ctx, url)
 }
 
-func getNews(url string) (*News, error) {
+func (c *Claquete) getNews(ctx context.Context, url string) (*News, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var result *News
 	var err error
 
-	c := NewClaquete()
-	c.collector.OnResponse(func(r *colly.Response) {
-		if r.StatusCode >= 200 && r.StatusCode < 301 {
-			result = &News{}
+	collector := c.collect(ctx)
+	collector.OnResponse(func(r *colly.Response) {
+		result, err = ParseNews(bytes.NewReader(r.Body), r.Request.URL.String())
+		if result != nil {
+			result.Headline.c = c
 		}
 	})
-	c.collector.OnHTML("body > div.conteudo > div.noticias", func(e *colly.HTMLElement) {
-		html, errHTML := e.DOM.Html()
-		if errHTML != nil {
-			err = errors.Wrapf(errHTML, "couldn't retrive html")
-		} else if result != nil {
-			result.HTML = strings.TrimSpace(html)
-
-			ds := e.DOM.Find("span:nth-child(1)")
-			if ds.Length() != 0 {
-				d, _, errDate := util.CreateDate(util.GetText("", ds), " de ")
-				if errDate != nil {
-					// TODO: proper error handling
-					fmt.Println(errDate)
-				} else {
-					result.Headline.Date = &d
-				}
-			}
+	errVisit := collector.Visit(url)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errVisit != nil {
+		return nil, errVisit
+	}
 
-			as := e.DOM.Find("span:nth-child(4)")
-			if as.Length() != 0 {
-				result.Author = util.GetText("", as)
-			}
+	return result, err
+}
 
-			hs := e.DOM.Find("h1")
-			if hs.Length() != 0 {
-				result.Headline.Title = util.GetText("", hs)
-			}
+// ParseNews parses a news page read from r. pageURL is the address the
+// page was retrieved from, as in
+// http://claquete.com.br/noticia/10425/noticia.html
+//
+// A page without news results in a *ParseError wrapping ErrNotFound.
+// Missing fields are reported in the news Diagnostics.
+func ParseNews(r io.Reader, pageURL string) (*News, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
 
-			cs := e.DOM.Find("p")
-			if cs.Length() != 0 {
-				result.Content = util.GetText("", cs)
-			}
+	result := &News{}
 
-			slug := util.CreateSlug(result.Headline.Title)
-			result.Page = strings.Replace(url, "noticia.html", slug+".html", 1)
-			result.Headline.NewsPage = result.Page
+	e := doc.Find(selNews).First()
+	if e.Length() == 0 {
+		return nil, &ParseError{URL: pageURL, Selector: selNews, Err: ErrNotFound}
+	}
 
-			if result.Author == "" {
-				err = errors.Wrap(err, "couldn't find author")
-			}
+	html, errHTML := e.Html()
+	if errHTML != nil {
+		return nil, errors.Wrapf(errHTML, "couldn't retrive html")
+	}
+	result.HTML = strings.TrimSpace(html)
 
-			if result.Content == "" {
-				err = errors.Wrap(err, "couldn't find content")
-			}
+	ds := e.Find(selNewsDate)
+	if ds.Length() != 0 {
+		text := util.GetText("", ds)
+		d, _, errDate := util.CreateDate(text, " de ")
+		if errDate != nil {
+			result.Diagnostics.add(pageURL, selNewsDate, text, errDate)
+		} else {
+			result.Headline.Date = &d
+		}
+	}
 
-			if result.Headline.Title == "" {
-				err = errors.Wrap(err, "couldn't find headline")
-			}
+	as := e.Find(selNewsAuthor)
+	if as.Length() != 0 {
+		result.Author = util.GetText("", as)
+	}
 
-			if result.Headline.Date.IsZero() {
-				err = errors.Wrap(err, "couldn't find date")
-			}
-		}
-	})
-	err = c.collector.Visit(url)
+	hs := e.Find(selNewsTitle)
+	if hs.Length() != 0 {
+		result.Headline.Title = util.GetText("", hs)
+	}
 
-	return result, err
+	cs := e.Find(selNewsContent)
+	if cs.Length() != 0 {
+		result.Content = util.GetText("", cs)
+	}
+
+	slug := util.CreateSlug(result.Headline.Title)
+	result.Page = strings.Replace(pageURL, "noticia.html", slug+".html", 1)
+	result.Headline.NewsPage = result.Page
+
+	if result.Author == "" {
+		result.Diagnostics.add(pageURL, selNewsAuthor, "", ErrLayoutChanged)
+	}
+
+	if result.Content == "" {
+		result.Diagnostics.add(pageURL, selNewsContent, "", ErrLayoutChanged)
+	}
+
+	if result.Headline.Title == "" {
+		result.Diagnostics.add(pageURL, selNewsTitle, "", ErrLayoutChanged)
+	}
+
+	if result.Headline.Date == nil {
+		result.Diagnostics.add(pageURL, selNewsDate, "", ErrLayoutChanged)
+	}
+
+	return result, nil
 }
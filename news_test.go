@@ -1,11 +1,14 @@
 package claquete
 
 import (
+	"errors"
+	"os"
+	"strings"
 	"testing"
 )
 
 func TestGetHeadlines(t *testing.T) {
-	results, err := GetHeadlines()
+	results, err := GetHeadlines(fixtures(t))
 	if err != nil {
 		t.Fatal("expected no error")
 	}
@@ -22,7 +25,7 @@ func TestGetHeadlines(t *testing.T) {
 }
 
 func TestGetNewsByID(t *testing.T) {
-	result, err := GetNewsByID(10425)
+	result, err := GetNewsByID(10425, fixtures(t))
 	expected := News{
 		Author: "Fernanda Mendes",
 		Headline: Headline{
@@ -47,3 +50,85 @@ func TestGetNewsByID(t *testing.T) {
 		t.Fatalf("expected page %s, but got %s", expected.Page, result.Page)
 	}
 }
+
+func TestParseHeadlines(t *testing.T) {
+	f, err := os.Open("testdata/noticias.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	results, err := ParseHeadlines(f, "http://claquete.com.br/noticias.html")
+	if err != nil {
+		t.Fatal("expected no error")
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 headlines, got %d", len(results))
+	}
+
+	first := results[0]
+	if first.Image == "" {
+		t.Fatal("expected image url in first headline but got nothing")
+	}
+
+	expectedPage := "http://claquete.com.br/noticia/10425/paris-filmes-fecha-contrato-para-filme-sobre-ney-matogrosso.html"
+	if first.NewsPage != expectedPage {
+		t.Fatalf("expected page %s, but got %s", expectedPage, first.NewsPage)
+	}
+
+	if results[1].Category != "Bilheteria" {
+		t.Fatalf("expected category Bilheteria, but got %s", results[1].Category)
+	}
+}
+
+func TestParseHeadlinesWithoutDate(t *testing.T) {
+	page := `<html><body><div class="conteudo"><div class="noticias">
+		<div><div class="subn">Bilheteria</div></div>
+		<h2><a href="/noticia/1/sem-data.html">Sem data</a></h2>
+	</div></div></body></html>`
+
+	results, errs, err := parseHeadlines(strings.NewReader(page), "http://claquete.com.br/noticias.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no headlines, got %+v", results)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrLayoutChanged) {
+		t.Fatalf("expected a layout changed parse error, got %v", errs)
+	}
+}
+
+func TestParseNews(t *testing.T) {
+	f, err := os.Open("testdata/noticia-10425.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	result, err := ParseNews(f, "http://claquete.com.br/noticia/10425/noticia.html")
+	if err != nil {
+		t.Fatal("expected no error")
+	}
+
+	expected := News{
+		Author: "Fernanda Mendes",
+		Headline: Headline{
+			Title: "Paris Filmes fecha contrato para filme sobre Ney Matogrosso",
+		},
+		Page: "http://claquete.com.br/noticia/10425/paris-filmes-fecha-contrato-para-filme-sobre-ney-matogrosso.html",
+	}
+
+	if result.Headline.Title != expected.Headline.Title {
+		t.Fatalf("expected title %s, but got %s", expected.Headline.Title, result.Headline.Title)
+	}
+
+	if result.Author != expected.Author {
+		t.Fatalf("expected author %s, but got %s", expected.Author, result.Author)
+	}
+
+	if result.Page != expected.Page {
+		t.Fatalf("expected page %s, but got %s", expected.Page, result.Page)
+	}
+}
@@ -0,0 +1,290 @@
+package claquete
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dsbezerra/claqueteapi/util"
+)
+
+var (
+	reNoteDate = regexp.MustCompile(`^\(?(\d{1,2})/(\d{1,2})(?:/(\d{2,4}))?\)?$`)
+
+	noteWeekdays = map[string]time.Weekday{
+		"dom": time.Sunday, "domingo": time.Sunday, "domingos": time.Sunday,
+		"seg": time.Monday, "segunda": time.Monday, "segundas": time.Monday,
+		"ter": time.Tuesday, "terça": time.Tuesday, "terca": time.Tuesday, "terças": time.Tuesday,
+		"qua": time.Wednesday, "quarta": time.Wednesday, "quartas": time.Wednesday,
+		"qui": time.Thursday, "quinta": time.Thursday, "quintas": time.Thursday,
+		"sex": time.Friday, "sexta": time.Friday, "sextas": time.Friday,
+		"sáb": time.Saturday, "sab": time.Saturday, "sábado": time.Saturday, "sabado": time.Saturday,
+		"sábados": time.Saturday, "sabados": time.Saturday,
+	}
+
+	// noteFillers are words carrying no meaning of their own in notes.
+	noteFillers = map[string]bool{
+		"e": true, "de": true, "do": true, "da": true, "dia": true, "dias": true,
+		"com": true, "no": true, "na": true, "nos": true, "nas": true, "em": true, "feira": true,
+		"sessão": true, "sessao": true, "sessões": true, "sessoes": true,
+	}
+)
+
+// parseNote parses the hint of the note with the given letter, as in
+// "Somente Sáb. (18/05) Dom. (19/05)", "Exceto de 12/05 a 15/05",
+// "Somente sáb e dom", "Pré-venda" or "Sessão com Libras". Dates without year are in the one
+// making them closest to ref, usually the start of the schedule period, in
+// its location. Words that couldn't be understood are returned in unknown.
+func parseNote(letter, hint string, ref time.Time) (note Note, unknown []string) {
+	note = Note{Letter: letter, Hint: hint}
+
+	// Features such as "Sessão com audiodescrição" may span several words
+	features, text := featuresIn(hint)
+	note.Features = features
+	text = strings.NewReplacer(
+		"pré-venda", " prevenda ", "pre-venda", " prevenda ", "pré venda", " prevenda ",
+		"pré-estreia", " preestreia ", "pré-estréia", " preestreia ", "pre-estreia", " preestreia ",
+		"pré estreia", " preestreia ", "-feira", " ", ".", " ", ",", " ", ":", " ", ";", " ",
+	).Replace(text)
+	words := strings.Fields(text)
+
+	// A weekday followed by a date, as in "sáb (18/05)", names that date
+	// only.
+	var weekday *time.Weekday
+	flush := func() {
+		if weekday != nil {
+			note.Weekdays = append(note.Weekdays, *weekday)
+			weekday = nil
+		}
+	}
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+
+		if d, ok := parseNoteDate(w, ref); ok {
+			weekday = nil
+			// Date range as in "de 12/05 a 15/05" or "12/05 até 15/05"
+			if i+2 < len(words) && (words[i+1] == "a" || words[i+1] == "até" || words[i+1] == "ate" || words[i+1] == "ao") {
+				if end, ok := parseNoteDate(words[i+2], ref); ok {
+					if end.Before(d) {
+						end = end.AddDate(1, 0, 0)
+					}
+					for day := d; !day.After(end); day = day.AddDate(0, 0, 1) {
+						note.Days = append(note.Days, day)
+					}
+					i += 2
+					continue
+				}
+			}
+			note.Days = append(note.Days, d)
+			continue
+		}
+
+		flush()
+		if wd, ok := noteWeekdays[w]; ok {
+			weekday = &wd
+			continue
+		}
+
+		switch {
+		case w == "somente" || w == "só" || w == "apenas" || w == "exclusivamente":
+			note.Type = NoteOnlyDayX
+		case w == "exceto" || w == "menos" || w == "exceção" || w == "exceçao":
+			note.Type = NoteExceptDayX
+		case w == "prevenda":
+			note.PreSale = true
+		case w == "preestreia" || w == "estreia" || w == "estréia":
+			note.Premiere = true
+		case noteFillers[w]:
+		default:
+			unknown = append(unknown, w)
+		}
+	}
+	flush()
+
+	return note, unknown
+}
+
+// parseNoteDate parses a date as in "(18/05)", "18/05" or "18/05/2019".
+// Without year, the date is the one closest to ref, so "02/01" is in the
+// next year of a period starting at 28/12.
+func parseNoteDate(s string, ref time.Time) (time.Time, bool) {
+	res := reNoteDate.FindStringSubmatch(s)
+	if len(res) != 4 {
+		return time.Time{}, false
+	}
+	day, _ := strconv.Atoi(res[1])
+	month, _ := strconv.Atoi(res[2])
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	if res[3] != "" {
+		year, _ := strconv.Atoi(res[3])
+		if year < 100 {
+			year += 2000
+		}
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, ref.Location()), true
+	}
+
+	var result time.Time
+	for year := ref.Year() - 1; year <= ref.Year()+1; year++ {
+		t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, ref.Location())
+		if result.IsZero() || absDuration(t.Sub(ref)) < absDuration(result.Sub(ref)) {
+			result = t
+		}
+	}
+	return result, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Restricts reports whether the note limits the days a session is played.
+func (n Note) Restricts() bool {
+	return n.Type != "" && (len(n.Days) > 0 || len(n.Weekdays) > 0)
+}
+
+// Includes reports whether a session with the note is played in the day
+// of t.
+func (n Note) Includes(t time.Time) bool {
+	if !n.Restricts() {
+		return true
+	}
+
+	match := false
+	for _, wd := range n.Weekdays {
+		if t.Weekday() == wd {
+			match = true
+			break
+		}
+	}
+	y, m, d := t.Date()
+	for _, day := range n.Days {
+		dy, dm, dd := day.Date()
+		if dy == y && dm == m && dd == d {
+			match = true
+			break
+		}
+	}
+
+	if n.Type == NoteExceptDayX {
+		return !match
+	}
+	return match
+}
+
+// Note returns the note with the given letter.
+func (sched *Schedule) Note(letter string) (Note, bool) {
+	for _, n := range sched.Notes {
+		if n.Letter == letter {
+			return n, true
+		}
+	}
+	return Note{}, false
+}
+
+// fillNotes parses the notes of the schedule, each a letter in s with
+// its text in the data-hint of the parent.
+func (sched *Schedule) fillNotes(s *goquery.Selection) {
+	s.Each(func(i int, ss *goquery.Selection) {
+		letter := strings.TrimSpace(ss.Text())
+		if _, ok := sched.Note(letter); ok {
+			return
+		}
+
+		hint := strings.TrimSpace(ss.Parent().AttrOr("data-hint", ""))
+		if hint == "" {
+			sched.Diagnostics.add(sched.page, "span.hleter", letter, ErrLayoutChanged)
+			return
+		}
+
+		note, unknown := parseNote(letter, hint, sched.periodStart())
+		if len(unknown) > 0 {
+			sched.Diagnostics.add(sched.page, "span.hleter", hint, fmt.Errorf("unknown words %q in note %s: %w", unknown, letter, ErrLayoutChanged))
+		}
+		if note.Type != "" && !note.Restricts() {
+			sched.Diagnostics.add(sched.page, "span.hleter", hint, fmt.Errorf("no days in note %s: %w", letter, ErrLayoutChanged))
+		}
+		sched.Notes = append(sched.Notes, note)
+	})
+}
+
+// sessionDays returns the days a session with the given notes is played,
+// every day of the schedule period unless restricted by notes. Sessions
+// only played in specific dates, such as pre-sales, may be out of the
+// period.
+func (sched *Schedule) sessionDays(notes []Note) []time.Time {
+	var candidates []time.Time
+	for _, n := range notes {
+		if n.Type == NoteOnlyDayX && len(n.Days) > 0 && len(n.Weekdays) == 0 {
+			for _, day := range n.Days {
+				y, m, d := day.Date()
+				candidates = append(candidates, time.Date(y, m, d, 0, 0, 0, 0, sched.location()))
+			}
+			break
+		}
+	}
+	if candidates == nil && sched.Period != nil {
+		for d := sched.Period.Start; !d.After(sched.Period.End); d = d.AddDate(0, 0, 1) {
+			candidates = append(candidates, d)
+		}
+	}
+
+	var result []time.Time
+	for _, day := range candidates {
+		included := true
+		for _, n := range notes {
+			if !n.Includes(day) {
+				included = false
+				break
+			}
+		}
+		if included {
+			result = append(result, day)
+		}
+	}
+	return result
+}
+
+// location returns the time zone of the schedule cinema, which defaults
+// to the one of Brasília when unknown.
+func (sched *Schedule) location() *time.Location {
+	if sched.loc != nil {
+		return sched.loc
+	}
+	return util.LoadLocation("America/Sao_Paulo")
+}
+
+// periodStart returns the start of the schedule period, or the current
+// day when unknown.
+func (sched *Schedule) periodStart() time.Time {
+	if sched.Period != nil {
+		return sched.Period.Start
+	}
+	y, m, d := time.Now().In(sched.location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, sched.location())
+}
+
+// splitNoteLetters splits a session time as in "17h30AB" into the time
+// and its note letters.
+func splitNoteLetters(s string) (string, []string) {
+	var letters []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if !unicode.IsLetter(r) || r == 'h' {
+			break
+		}
+		letters = append([]string{string(r)}, letters...)
+		s = s[:len(s)-size]
+	}
+	return s, letters
+}
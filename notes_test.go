@@ -0,0 +1,129 @@
+package claquete
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNote(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	ref := time.Date(2019, time.May, 16, 0, 0, 0, 0, loc)
+
+	cases := []struct {
+		hint     string
+		typ      NoteType
+		days     []string
+		weekdays []time.Weekday
+		preSale  bool
+		premiere bool
+	}{
+		{"Somente Sáb. (18/05) Dom. (19/05)", NoteOnlyDayX, []string{"18/05", "19/05"}, nil, false, false},
+		{"Exceto Qua. (22/05)", NoteExceptDayX, []string{"22/05"}, nil, false, false},
+		{"Exceto de 12/05 a 15/05", NoteExceptDayX, []string{"12/05", "13/05", "14/05", "15/05"}, nil, false, false},
+		{"Somente sáb e dom", NoteOnlyDayX, nil, []time.Weekday{time.Saturday, time.Sunday}, false, false},
+		{"Exceto segunda-feira", NoteExceptDayX, nil, []time.Weekday{time.Monday}, false, false},
+		{"Pré-venda", "", nil, nil, true, false},
+		{"Pré-estreia somente 30/04", NoteOnlyDayX, []string{"30/04"}, nil, false, true},
+	}
+	for _, c := range cases {
+		n, unknown := parseNote("A", c.hint, ref)
+		if len(unknown) > 0 {
+			t.Fatalf("%s: unexpected unknown words %q", c.hint, unknown)
+		}
+		if n.Type != c.typ || n.PreSale != c.preSale || n.Premiere != c.premiere {
+			t.Fatalf("%s: unexpected note %+v", c.hint, n)
+		}
+		if len(n.Days) != len(c.days) || len(n.Weekdays) != len(c.weekdays) {
+			t.Fatalf("%s: unexpected days in %+v", c.hint, n)
+		}
+		for i, d := range c.days {
+			if n.Days[i].Format("02/01") != d {
+				t.Fatalf("%s: expected %s, got %s", c.hint, d, n.Days[i].Format("02/01"))
+			}
+		}
+		for i, wd := range c.weekdays {
+			if n.Weekdays[i] != wd {
+				t.Fatalf("%s: expected %s, got %s", c.hint, wd, n.Weekdays[i])
+			}
+		}
+	}
+
+	if n, _ := parseNote("A", "Exceto de 30/12 a 02/01", time.Date(2019, time.December, 28, 0, 0, 0, 0, loc)); len(n.Days) != 4 || n.Days[3].Year() != 2020 {
+		t.Fatalf("expected 4 days until 2020, got %+v", n.Days)
+	}
+
+	if n, unknown := parseNote("A", "Sessão com audiodescrição e Libras", ref); len(unknown) > 0 || !n.Features.Has(FeatureAudioDescription) || !n.Features.Has(FeatureLibras) {
+		t.Fatalf("unexpected note %+v with unknown words %q", n, unknown)
+	}
+
+	if _, unknown := parseNote("A", "Somente feriados", ref); len(unknown) != 1 || unknown[0] != "feriados" {
+		t.Fatalf("expected feriados to be unknown, got %q", unknown)
+	}
+}
+
+func TestSplitNoteLetters(t *testing.T) {
+	clock, letters := splitNoteLetters("17h30AB")
+	if clock != "17h30" || strings.Join(letters, "") != "AB" {
+		t.Fatalf("expected 17h30 and AB, got %s and %v", clock, letters)
+	}
+	clock, letters = splitNoteLetters("21h00")
+	if clock != "21h00" || len(letters) != 0 {
+		t.Fatalf("expected 21h00 and no letters, got %s and %v", clock, letters)
+	}
+}
+
+func TestParseScheduleNotes(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		`<div data-hint="Exceto Qua. (22/05)"><span class="hleter">B</span></div>`,
+		`<div data-hint="Exceto Qua. (22/05)"><span class="hleter">B</span></div>
+			<div data-hint="Pré-venda"><span class="hleter">C</span></div>
+			<div data-hint="Somente sáb e dom"><span class="hleter">D</span></div>`,
+		"17h30A", "17h30AC",
+		"20h15", "20h15D, 23h00Z",
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if len(sched.Notes) != 4 {
+		t.Fatalf("expected 4 notes, got %+v", sched.Notes)
+	}
+	a, ok := sched.Note("A")
+	if !ok || a.Type != NoteOnlyDayX || len(a.Days) != 2 {
+		t.Fatalf("unexpected note %+v", a)
+	}
+
+	var presale, weekend, unknown int
+	for _, s := range sched.Sessions {
+		switch {
+		case s.Notes == "AC":
+			presale++
+			if !s.PreSale {
+				t.Fatalf("expected session %+v to be in pre-sale", s)
+			}
+		case s.Notes == "D":
+			weekend++
+			if wd := s.StartTime.Weekday(); wd != time.Saturday && wd != time.Sunday {
+				t.Fatalf("expected session %+v on the weekend", s)
+			}
+		case s.StartTime.Hour() == 23:
+			unknown++
+		}
+	}
+	// Sessions with an unknown note are kept for every day of the period
+	if presale != 2 || weekend != 2 || unknown != 7 {
+		t.Fatalf("expected 2, 2 and 7 sessions, got %d, %d and %d", presale, weekend, unknown)
+	}
+
+	if len(sched.Diagnostics) != 1 || sched.Diagnostics[0].Text != "23h00Z" {
+		t.Fatalf("expected a diagnostic for 23h00Z, got %v", sched.Diagnostics)
+	}
+}
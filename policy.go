@@ -0,0 +1,70 @@
+package claquete
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dsbezerra/claqueteapi/httppolicy"
+)
+
+// RateLimit limits the requests made by the Claquete to at most
+// concurrency in flight per domain, started at least delay apart plus a
+// random jitter up to jitter. A zero concurrency means no limit.
+func RateLimit(concurrency int, delay, jitter time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		p := c.httpPolicy()
+		p.Concurrency = concurrency
+		p.Delay = delay
+		p.Jitter = jitter
+	}
+}
+
+// Retry makes the Claquete retry requests that fail with a 429 or 5xx
+// status, a timeout or a connection reset up to retries times, waiting
+// an exponential backoff from min up to max between them.
+func Retry(retries int, min, max time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		p := c.httpPolicy()
+		p.Retries = retries
+		p.MinBackoff = min
+		p.MaxBackoff = max
+	}
+}
+
+// CircuitBreaker makes the Claquete fail fast with
+// httppolicy.ErrCircuitOpen after threshold consecutive failed requests
+// to the website, until cooldown passes and a request succeeds again.
+func CircuitBreaker(threshold int, cooldown time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		p := c.httpPolicy()
+		p.Threshold = threshold
+		p.Cooldown = cooldown
+	}
+}
+
+// UserAgent sets the User-Agent sent by the Claquete, which otherwise
+// picks a random one from util.UserAgents.
+func UserAgent(ua string) func(*Claquete) {
+	return func(c *Claquete) {
+		c.userAgent = ua
+	}
+}
+
+// httpPolicy returns the policy configured by the options, creating it
+// on first use.
+func (c *Claquete) httpPolicy() *httppolicy.Transport {
+	if c.policy == nil {
+		c.policy = &httppolicy.Transport{}
+	}
+	return c.policy
+}
+
+// policyTransport wraps rt with the configured rate limit, retry and
+// circuit breaker, if any.
+func (c *Claquete) policyTransport(rt http.RoundTripper) http.RoundTripper {
+	if c.policy == nil {
+		return rt
+	}
+	c.policy.Base = rt
+	return c.policy
+}
@@ -0,0 +1,73 @@
+package claquete
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/claqueteapi/httppolicy"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("User-Agent") != "claquete-test" {
+			t.Errorf("unexpected user agent %s", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	c := NewClaquete(
+		SiteURL(ts.URL),
+		UserAgent("claquete-test"),
+		RateLimit(1, time.Millisecond, time.Millisecond),
+		Retry(2, time.Millisecond, 10*time.Millisecond),
+	)
+	sched, err := c.GetSchedule(656)
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if sched.Cinema.Name != "Cinemais Montes Claros" {
+		t.Fatalf("expected Cinemais Montes Claros, got %s", sched.Cinema.Name)
+	}
+	if hits != 2 {
+		t.Fatalf("expected 2 requests to server, got %d", hits)
+	}
+}
+
+func TestCircuitBreakerPolicy(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClaquete(SiteURL(ts.URL), CircuitBreaker(1, time.Minute))
+	if _, err := c.GetSchedule(656); err == nil {
+		t.Fatal("expected error")
+	}
+
+	_, err := c.GetMovie(8427)
+	if !errors.Is(err, httppolicy.ErrCircuitOpen) {
+		t.Fatalf("expected %s, got %v", httppolicy.ErrCircuitOpen, err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 request to server, got %d", hits)
+	}
+}
@@ -0,0 +1,339 @@
+package claquete
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Sessions is a list of sessions that can be queried, from a single
+	// Schedule or merged from several with MergeSessions.
+	Sessions []Session
+
+	// SessionFilter reports whether a session should be kept.
+	SessionFilter func(Session) bool
+
+	// SessionOrder compares two sessions, returning a negative number when
+	// a comes before b, a positive one when after and zero when equal.
+	SessionOrder func(a, b Session) int
+
+	// SessionGrouping returns the key of the group a session belongs to.
+	SessionGrouping func(Session) string
+
+	// SessionGroup is a group of sessions sharing the same key.
+	SessionGroup struct {
+		Key      string   `json:"key"`
+		Sessions Sessions `json:"sessions"`
+	}
+)
+
+// MergeSessions returns the sessions of every given schedule, in order.
+// Nil schedules are skipped.
+func MergeSessions(schedules ...*Schedule) Sessions {
+	var result Sessions
+	for _, sched := range schedules {
+		if sched != nil {
+			result = append(result, sched.Sessions...)
+		}
+	}
+	return result
+}
+
+// Query returns the sessions of sched matching every filter.
+func (sched *Schedule) Query(filters ...SessionFilter) Sessions {
+	return Sessions(sched.Sessions).Filter(filters...)
+}
+
+// Filter returns the sessions matching every filter.
+func (s Sessions) Filter(filters ...SessionFilter) Sessions {
+	var result Sessions
+	for _, session := range s {
+		if And(filters...)(session) {
+			result = append(result, session)
+		}
+	}
+	return result
+}
+
+// Sort returns a copy of the sessions sorted by the given orders, each
+// one breaking the ties of the previous.
+func (s Sessions) Sort(orders ...SessionOrder) Sessions {
+	result := append(Sessions(nil), s...)
+	sort.SliceStable(result, func(i, j int) bool {
+		for _, order := range orders {
+			if c := order(result[i], result[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return result
+}
+
+// Group splits the sessions by the given grouping. Groups are in the
+// order their first session appears, so sort the sessions before
+// grouping them to have ordered groups.
+func (s Sessions) Group(grouping SessionGrouping) []SessionGroup {
+	index := make(map[string]int)
+	var result []SessionGroup
+	for _, session := range s {
+		key := grouping(session)
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, SessionGroup{Key: key})
+		}
+		result[i].Sessions = append(result[i].Sessions, session)
+	}
+	return result
+}
+
+// And matches sessions matching every filter. It matches any session
+// when there are no filters.
+func And(filters ...SessionFilter) SessionFilter {
+	return func(s Session) bool {
+		for _, f := range filters {
+			if !f(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches sessions matching any of the filters.
+func Or(filters ...SessionFilter) SessionFilter {
+	return func(s Session) bool {
+		for _, f := range filters {
+			if f(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches sessions not matching filter.
+func Not(filter SessionFilter) SessionFilter {
+	return func(s Session) bool {
+		return !filter(s)
+	}
+}
+
+// OnDay matches sessions starting in the same day as t, in the time zone
+// of t.
+func OnDay(t time.Time) SessionFilter {
+	y, m, d := t.Date()
+	return func(s Session) bool {
+		if s.StartTime == nil {
+			return false
+		}
+		sy, sm, sd := s.StartTime.In(t.Location()).Date()
+		return sy == y && sm == m && sd == d
+	}
+}
+
+// Between matches sessions starting from from and before to.
+func Between(from, to time.Time) SessionFilter {
+	return func(s Session) bool {
+		return s.StartTime != nil && !s.StartTime.Before(from) && s.StartTime.Before(to)
+	}
+}
+
+// TimeOfDay matches sessions starting from from and before to since
+// midnight, in the time zone of the session, such as 18*time.Hour and
+// 23*time.Hour for evening sessions. A window crossing midnight, where to
+// is before from, is also accepted.
+func TimeOfDay(from, to time.Duration) SessionFilter {
+	return func(s Session) bool {
+		if s.StartTime == nil {
+			return false
+		}
+		t := *s.StartTime
+		at := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+		if to < from {
+			return at >= from || at < to
+		}
+		return at >= from && at < to
+	}
+}
+
+// WithMovie matches sessions of any of the given movies.
+func WithMovie(ids ...int) SessionFilter {
+	return func(s Session) bool {
+		return containsInt(ids, s.MovieID)
+	}
+}
+
+// WithCinema matches sessions in any of the given cinemas.
+func WithCinema(ids ...int) SessionFilter {
+	return func(s Session) bool {
+		return containsInt(ids, s.CinemaID)
+	}
+}
+
+// WithRoom matches sessions in any of the rooms with the given numbers.
+func WithRoom(numbers ...int) SessionFilter {
+	return func(s Session) bool {
+		return containsInt(numbers, s.Room.Number)
+	}
+}
+
+// WithRoomKind matches sessions in any of the given kinds of rooms, such
+// as RoomVIP.
+func WithRoomKind(kinds ...RoomKind) SessionFilter {
+	return func(s Session) bool {
+		for _, kind := range kinds {
+			if s.Room.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithFormat matches sessions in any of the given formats, such as
+// Format3D.
+func WithFormat(formats ...string) SessionFilter {
+	return func(s Session) bool {
+		return containsString(formats, s.Format)
+	}
+}
+
+// WithVersion matches sessions in any of the given versions, such as
+// VersionSubtitled.
+func WithVersion(versions ...string) SessionFilter {
+	return func(s Session) bool {
+		return containsString(versions, s.Version)
+	}
+}
+
+// WithFeature matches sessions with any of the given features, such as
+// FeatureDBox.
+func WithFeature(features ...Feature) SessionFilter {
+	return func(s Session) bool {
+		for _, f := range features {
+			if s.Features.Has(f) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithAccessibility matches sessions with every given accessibility
+// feature, such as FeatureLibras, or with any accessibility feature when
+// none is given.
+func WithAccessibility(needs ...Feature) SessionFilter {
+	return func(s Session) bool {
+		if len(needs) == 0 {
+			for _, f := range s.Features {
+				if f.Accessibility() {
+					return true
+				}
+			}
+			return false
+		}
+		for _, f := range needs {
+			if !s.Features.Has(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithVIP matches sessions in VIP rooms.
+func WithVIP() SessionFilter {
+	return WithFeature(FeatureVIP)
+}
+
+// WithXD matches sessions in XD rooms.
+func WithXD() SessionFilter {
+	return WithFeature(FeatureXD)
+}
+
+// WithIMAX matches sessions in IMAX rooms.
+func WithIMAX() SessionFilter {
+	return WithFeature(FeatureIMAX)
+}
+
+// ByStartTime orders sessions by start time, sessions without one last.
+func ByStartTime(a, b Session) int {
+	switch {
+	case a.StartTime == nil && b.StartTime == nil:
+		return 0
+	case a.StartTime == nil:
+		return 1
+	case b.StartTime == nil:
+		return -1
+	case a.StartTime.Before(*b.StartTime):
+		return -1
+	case b.StartTime.Before(*a.StartTime):
+		return 1
+	}
+	return 0
+}
+
+// ByMovieTitle orders sessions by movie title.
+func ByMovieTitle(a, b Session) int {
+	return strings.Compare(a.MovieTitle, b.MovieTitle)
+}
+
+// ByCinema orders sessions by cinema ID.
+func ByCinema(a, b Session) int {
+	return a.CinemaID - b.CinemaID
+}
+
+// ByRoom orders sessions by room number, then label.
+func ByRoom(a, b Session) int {
+	return compareRooms(a.Room, b.Room)
+}
+
+// Desc reverses order.
+func Desc(order SessionOrder) SessionOrder {
+	return func(a, b Session) int {
+		return order(b, a)
+	}
+}
+
+// GroupByMovie groups sessions by movie ID.
+func GroupByMovie(s Session) string {
+	return strconv.Itoa(s.MovieID)
+}
+
+// GroupByDay groups sessions by the day they start, as in 2019-05-23, in
+// the time zone of the session.
+func GroupByDay(s Session) string {
+	if s.StartTime == nil {
+		return ""
+	}
+	return s.StartTime.Format("2006-01-02")
+}
+
+// GroupByRoom groups sessions by cinema ID and room slug, as in 656-1 or
+// 656-sala-imax.
+func GroupByRoom(s Session) string {
+	return strconv.Itoa(s.CinemaID) + "-" + s.Room.Slug()
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
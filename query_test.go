@@ -0,0 +1,78 @@
+package claquete
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	at := func(day, hours, minutes int) *time.Time {
+		t := time.Date(2019, time.May, day, hours, minutes, 0, 0, loc)
+		return &t
+	}
+
+	a := &Schedule{Sessions: []Session{
+		{CinemaID: 656, MovieID: 8427, MovieTitle: "O Retorno de Mary Poppins", Format: Format3D, Version: VersionDubbed, Room: Room{Number: 1}, StartTime: at(23, 14, 0)},
+		{CinemaID: 656, MovieID: 8427, MovieTitle: "O Retorno de Mary Poppins", Format: Format2D, Version: VersionSubtitled, Room: Room{Number: 1}, StartTime: at(23, 21, 0)},
+		{CinemaID: 656, MovieID: 8600, MovieTitle: "Vingadores: Ultimato", Format: Format2D, Version: VersionSubtitled, Room: Room{Number: 2}, StartTime: at(24, 20, 15), Features: Features{FeatureVIP}},
+	}}
+	b := &Schedule{Sessions: []Session{
+		{CinemaID: 657, MovieID: 8600, MovieTitle: "Vingadores: Ultimato", Format: Format4DX, Version: VersionDubbed, Room: Room{Number: 1}, StartTime: at(23, 19, 0), Features: Features{FeatureIMAX}},
+		{CinemaID: 657, MovieID: 8600, MovieTitle: "Vingadores: Ultimato", Format: Format2D, Version: VersionSubtitled, Room: Room{Number: 3}, StartTime: at(23, 23, 30)},
+	}}
+
+	all := MergeSessions(a, nil, b)
+	if len(all) != 5 {
+		t.Fatalf("expected 5 sessions, got %d", len(all))
+	}
+
+	cases := []struct {
+		name     string
+		filters  []SessionFilter
+		expected int
+	}{
+		{"day", []SessionFilter{OnDay(*at(23, 0, 0))}, 4},
+		{"range", []SessionFilter{Between(*at(23, 20, 0), *at(24, 21, 0))}, 3},
+		{"evening", []SessionFilter{TimeOfDay(18*time.Hour, 22*time.Hour)}, 3},
+		{"late", []SessionFilter{TimeOfDay(23*time.Hour, 2*time.Hour)}, 1},
+		{"movie", []SessionFilter{WithMovie(8600)}, 3},
+		{"subtitled 2D", []SessionFilter{WithFormat(Format2D), WithVersion(VersionSubtitled)}, 3},
+		{"premium", []SessionFilter{Or(WithVIP(), WithIMAX(), WithXD())}, 2},
+		{"not dubbed", []SessionFilter{Not(WithVersion(VersionDubbed))}, 3},
+		{"room", []SessionFilter{WithCinema(657), WithRoom(1, 3)}, 2},
+		{"none", nil, 5},
+	}
+	for _, c := range cases {
+		if n := len(all.Filter(c.filters...)); n != c.expected {
+			t.Fatalf("%s: expected %d sessions, got %d", c.name, c.expected, n)
+		}
+	}
+
+	if n := len(a.Query(WithMovie(8427))); n != 2 {
+		t.Fatalf("expected 2 sessions, got %d", n)
+	}
+
+	sorted := all.Sort(ByMovieTitle, Desc(ByStartTime))
+	if sorted[0].StartTime != a.Sessions[1].StartTime || sorted[4].StartTime != b.Sessions[0].StartTime {
+		t.Fatalf("unexpected order %+v", sorted)
+	}
+	if all[0].StartTime != a.Sessions[0].StartTime {
+		t.Fatal("expected sessions to be left untouched")
+	}
+
+	days := all.Sort(ByStartTime).Group(GroupByDay)
+	if len(days) != 2 || days[0].Key != "2019-05-23" || len(days[0].Sessions) != 4 {
+		t.Fatalf("unexpected groups %+v", days)
+	}
+
+	rooms := all.Group(GroupByRoom)
+	if len(rooms) != 4 || rooms[0].Key != "656-1" || len(rooms[0].Sessions) != 2 {
+		t.Fatalf("unexpected groups %+v", rooms)
+	}
+
+	movies := all.Group(GroupByMovie)
+	if len(movies) != 2 || movies[1].Key != "8600" || len(movies[1].Sessions) != 3 {
+		t.Fatalf("unexpected groups %+v", movies)
+	}
+}
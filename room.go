@@ -0,0 +1,117 @@
+package claquete
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dsbezerra/claqueteapi/util"
+)
+
+// Room kinds
+const (
+	RoomStandard RoomKind = ""
+	RoomVIP      RoomKind = "vip"
+	RoomIMAX     RoomKind = "imax"
+	RoomXD       RoomKind = "xd"
+	RoomMacroXE  RoomKind = "macro_xe"
+	Room4DX      RoomKind = "4dx"
+)
+
+var (
+	reSessionTime = regexp.MustCompile(`^\d{1,2}h\d{2}`)
+
+	// roomFeatures are the features of sessions in each kind of room.
+	roomFeatures = map[RoomKind]Feature{
+		RoomVIP:     FeatureVIP,
+		RoomIMAX:    FeatureIMAX,
+		RoomXD:      FeatureXD,
+		RoomMacroXE: FeatureMacroXE,
+		Room4DX:     Feature4DX,
+	}
+
+	roomKinds = map[string]RoomKind{
+		"vip":   RoomVIP,
+		"imax":  RoomIMAX,
+		"xd":    RoomXD,
+		"macro": RoomMacroXE,
+		"xe":    RoomMacroXE,
+		"4dx":   Room4DX,
+	}
+)
+
+type (
+	// RoomKind is the kind of a room, such as RoomVIP.
+	RoomKind string
+
+	// Room is a cinema room as in "Sala 2", "Sala VIP 2" or "Sala IMAX".
+	Room struct {
+		// Label is the name of the room as shown in the schedule.
+		Label string `json:"label"`
+		// Number is the room number, or the first one for sessions shown
+		// in several rooms as in "Salas 3 e 4". It is zero for rooms
+		// without number, such as "Sala IMAX".
+		Number int      `json:"number,omitempty"`
+		Kind   RoomKind `json:"kind,omitempty"`
+	}
+)
+
+// String returns the label of the room.
+func (r Room) String() string {
+	if r.Label == "" && r.Number > 0 {
+		return "Sala " + strconv.Itoa(r.Number)
+	}
+	return r.Label
+}
+
+// Slug identifies the room within its cinema, as in "2" for "Sala 2" or
+// "sala-vip-2" for "Sala VIP 2".
+func (r Room) Slug() string {
+	if r.Number > 0 && r.String() == "Sala "+strconv.Itoa(r.Number) {
+		return strconv.Itoa(r.Number)
+	}
+	return util.CreateSlug(r.Label)
+}
+
+// parseRoom parses the room at the start of text as in "Sala VIP 2 20h15,
+// 21h00", returning the remaining session times. Words that couldn't be
+// understood are kept in the label and returned in unknown.
+func parseRoom(text string) (room Room, remainder string, unknown []string) {
+	words := strings.Fields(text)
+
+	i := 0
+	for ; i < len(words); i++ {
+		w := words[i]
+		if reSessionTime.MatchString(w) {
+			break
+		}
+
+		lw := strings.ToLower(strings.TrimSuffix(w, ","))
+		if n, err := strconv.Atoi(lw); err == nil {
+			if room.Number == 0 {
+				room.Number = n
+			}
+			continue
+		}
+		if kind, ok := roomKinds[lw]; ok {
+			room.Kind = kind
+			continue
+		}
+		switch lw {
+		case "sala", "salas", "e", "&", "-":
+		default:
+			unknown = append(unknown, w)
+		}
+	}
+
+	room.Label = strings.TrimSuffix(strings.Join(words[:i], " "), ",")
+	return room, strings.Join(words[i:], " "), unknown
+}
+
+// compareRooms orders rooms by number, then label.
+func compareRooms(a, b Room) int {
+	if a.Number != b.Number {
+		return a.Number - b.Number
+	}
+	return strings.Compare(a.Label, b.Label)
+}
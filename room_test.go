@@ -0,0 +1,55 @@
+package claquete
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestParseRoom(t *testing.T) {
+	cases := []struct {
+		text      string
+		expected  Room
+		remainder string
+	}{
+		{"Sala 2 20h15, 21h00", Room{Label: "Sala 2", Number: 2}, "20h15, 21h00"},
+		{"Sala VIP 2 14h00", Room{Label: "Sala VIP 2", Number: 2, Kind: RoomVIP}, "14h00"},
+		{"Sala IMAX 14h00", Room{Label: "Sala IMAX", Kind: RoomIMAX}, "14h00"},
+		{"Salas 3 e 4 18h30A", Room{Label: "Salas 3 e 4", Number: 3}, "18h30A"},
+		{"Sala Macro XE 21h00", Room{Label: "Sala Macro XE", Kind: RoomMacroXE}, "21h00"},
+	}
+	for _, c := range cases {
+		room, remainder, unknown := parseRoom(c.text)
+		if len(unknown) > 0 {
+			t.Fatalf("%s: unexpected unknown words %q", c.text, unknown)
+		}
+		if room != c.expected || remainder != c.remainder {
+			t.Fatalf("%s: expected %+v and %s, got %+v and %s", c.text, c.expected, c.remainder, room, remainder)
+		}
+	}
+
+	if slug := (Room{Label: "Sala VIP 2", Number: 2, Kind: RoomVIP}).Slug(); slug != "sala-vip-2" {
+		t.Fatalf("expected sala-vip-2, got %s", slug)
+	}
+	if slug := (Room{Number: 2}).Slug(); slug != "2" {
+		t.Fatalf("expected 2, got %s", slug)
+	}
+}
+
+func TestParseScheduleRooms(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer("Sala 2 20h15", "Sala IMAX 20h15").Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	imax := sched.Query(WithRoomKind(RoomIMAX))
+	if len(imax) != 7 || !imax[0].Features.Has(FeatureIMAX) || imax[0].Room.Label != "Sala IMAX" {
+		t.Fatalf("expected 7 IMAX sessions, got %+v", imax)
+	}
+}
@@ -1,15 +1,17 @@
 package claquete
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/dsbezerra/claqueteapi/movieutil"
@@ -44,6 +46,8 @@ const (
 var (
 	// PeriodExp TODO
 	PeriodExp = regexp.MustCompile("\\((\\d{2}\\/\\d{2})\\)")
+
+	reCinemaID = regexp.MustCompile("\\/programacao\\/(\\d+)")
 )
 
 type (
@@ -52,8 +56,14 @@ type (
 		Cinema   *Cinema   `json:"cinema"`
 		Period   *Period   `json:"period"`
 		Sessions []Session `json:"sessions"`
-		loc      *time.Location
-		dis      *NoteMap
+		// Diagnostics lists the parts of the page that couldn't be parsed
+		// and were skipped.
+		Diagnostics ParseErrors `json:"diagnostics,omitempty"`
+		// Notes are the notes referred by letter in sessions, restricting
+		// the days they are played or marking them as pre-sale or premiere.
+		Notes []Note `json:"notes,omitempty"`
+		loc   *time.Location
+		page  string
 	}
 
 	// Period TODO
@@ -70,29 +80,70 @@ type (
 		Format     string     `json:"format"`
 		Version    string     `json:"version"`
 		StartTime  *time.Time `json:"opening_time"`
-		Room       int        `json:"room"`
-		VIP        bool       `json:"vip"`
-		XD         bool       `json:"xd"`
-		IMAX       bool       `json:"imax"`
+		// EndTime is when the session ends, only known once set with
+		// SetEndTimes.
+		EndTime *time.Time `json:"end_time,omitempty"`
+		// Room is encoded as room_info, the room field holding its number
+		// as in previous versions.
+		Room Room `json:"room_info"`
+		// Features are the features of the session room, such as
+		// FeatureVIP, including the icon hints not known by the package.
+		Features Features `json:"features,omitempty"`
+		// Notes are the letters of the schedule notes applying to the
+		// session, see Schedule.Note.
+		Notes    string `json:"notes,omitempty"`
+		PreSale  bool   `json:"pre_sale,omitempty"`
+		Premiere bool   `json:"premiere,omitempty"`
 	}
 
 	// NoteMap TODO
+	//
+	// Deprecated: notes are in Schedule.Notes.
 	NoteMap struct {
 		sync.RWMutex
 		m map[string]Note
 	}
 
-	// Note TODO
+	// Note is a remark about some sessions of a schedule, referred to by
+	// its letter. Notes of type NoteOnlyDayX or NoteExceptDayX restrict
+	// the days sessions are played to or from the given days and weekdays.
 	Note struct {
-		Type NoteType    `json:"type"`
-		Days []time.Time `json:"days"`
+		Letter   string         `json:"letter"`
+		Hint     string         `json:"hint"`
+		Type     NoteType       `json:"type"`
+		Days     []time.Time    `json:"days"`
+		Weekdays []time.Weekday `json:"weekdays,omitempty"`
+		PreSale  bool           `json:"pre_sale,omitempty"`
+		Premiere bool           `json:"premiere,omitempty"`
+		// Features are the features of sessions with the note, such as
+		// FeatureAudioDescription.
+		Features Features `json:"features,omitempty"`
 	}
 )
 
 // GetSchedule TODO
-func GetSchedule(cinema int) (*Schedule, error) {
+func GetSchedule(cinema int, options ...Options) (*Schedule, error) {
+	return GetScheduleContext(context.Background(), cinema, options...)
+}
+
+// GetScheduleContext is like GetSchedule but aborts when ctx is done.
+func GetScheduleContext(ctx context.Context, cinema int, options ...Options) (*Schedule, error) {
+	return NewClaquete(options...).GetScheduleContext(ctx, cinema)
+}
+
+// GetSchedule TODO
+func (c *Claquete) GetSchedule(cinema int) (*Schedule, error) {
+	return c.GetScheduleContext(context.Background(), cinema)
+}
+
+// GetScheduleContext is like GetSchedule but aborts when ctx is done.
+func (c *Claquete) GetScheduleContext(ctx context.Context, cinema int) (*Schedule, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	if cinema < 0 {
-		return nil, errors.New("invalid ID")
+		return nil, fmt.Errorf("cinema ID %d: %w", cinema, ErrInvalidArgument)
 	}
 
 	idStr := strconv.Itoa(cinema)
@@ -103,147 +154,116 @@ func GetSchedule(cinema int) (*Schedule, error) {
 	var result *Schedule
 	var err error
 
-	c := NewClaquete()
+	collector := c.collect(ctx)
 
-	c.collector.OnHTML("body > div.conteudo >div.progrb", func(e *colly.HTMLElement) {
-		schedule, err := parseSchedule(cinema, e.DOM)
-		if err != nil {
-			return
+	collector.OnResponse(func(r *colly.Response) {
+		result, err = ParseSchedule(bytes.NewReader(r.Body), r.Request.URL.String())
+		if result != nil {
+			result.Cinema.c = c
 		}
-		result = schedule
 	})
 
 	// cinema-%s.html is optional, the request is successful with any string
 	// followed by .html
-	u := fmt.Sprintf("%s/programacao/%s/cinema-%s.html", BaseURL, idStr, idStr)
-	err = c.collector.Visit(u)
+	u := c.url(fmt.Sprintf("/programacao/%s/cinema-%s.html", idStr, idStr))
+	errVisit := collector.Visit(u)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errVisit != nil {
+		return nil, errVisit
+	}
 	return result, err
 }
 
-func (sched *Schedule) fillDisclaimer(s *goquery.Selection) {
-	s.Each(func(i int, ss *goquery.Selection) {
-		n := strings.TrimSpace(ss.Text())
-		sched.dis.RLock()
-		_, ok := sched.dis.m[n]
-		sched.dis.RUnlock()
-		if !ok {
-			hint := ss.Parent().AttrOr("data-hint", "")
-			if hint == "" {
-				fmt.Printf("couldn't find hint for note %s\n", n)
-			} else {
+// ParseSchedule parses a cinema program page read from r. pageURL is the
+// address the page was retrieved from and identifies the cinema, as in
+// http://claquete.com.br/programacao/656/cinema-656.html
+//
+// A page without a schedule results in a *ParseError wrapping ErrNotFound.
+// Movies, sessions and notes that couldn't be parsed are skipped and
+// reported in the schedule Diagnostics.
+func ParseSchedule(r io.Reader, pageURL string) (*Schedule, error) {
+	res := reCinemaID.FindStringSubmatch(pageURL)
+	if len(res) != 2 {
+		return nil, fmt.Errorf("couldn't find cinema ID in URL %s: %w", pageURL, ErrInvalidArgument)
+	}
 
-				var result Note
-				hint = strings.TrimSpace(strings.ToLower(hint))
-				t, remainder := util.BreakBySpaces(hint)
-				if strings.Contains(t, "somente") {
-					result.Type = NoteOnlyDayX
-				} else if strings.Contains(t, "exceto") {
-					result.Type = NoteExceptDayX
-				} else {
-					fmt.Printf("unknown value '%s' for type\n", t)
-				}
+	cinema, err := strconv.Atoi(res[1])
+	if err != nil {
+		return nil, err
+	}
 
-				if result.Type != "" {
-					// Parse day
-					remainder = strings.Replace(remainder, ".", " ", -1)
-
-					var day, date string
-					// Get the day(s) now
-					for remainder != "" {
-						day, remainder = util.BreakBySpaces(remainder)
-						if day == "" {
-							break
-						}
-
-						if remainder == "" {
-							break
-						}
-
-						date, remainder = util.BreakBySpaces(remainder)
-						if date == "" {
-							break
-						}
-
-						res := PeriodExp.FindStringSubmatch(date)
-						if len(res) == 2 {
-							d, err := util.StringToTime(res[1], "/", sched.loc)
-							if err != nil {
-								fmt.Printf("Failed to create date from text '%s'\n", res[1])
-							} else {
-								result.Days = append(result.Days, *d)
-							}
-						}
-					}
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
 
-					sched.dis.Lock()
-					sched.dis.m[n] = result
-					sched.dis.Unlock()
-				}
-			}
-		}
-	})
+	s := doc.Find(selSchedule)
+	if s.Length() == 0 {
+		return nil, &ParseError{URL: pageURL, Selector: selSchedule, Err: ErrNotFound}
+	}
+
+	return parseSchedule(cinema, s.First(), pageURL)
 }
 
-func parseSchedule(c int, s *goquery.Selection) (*Schedule, error) {
+func parseSchedule(c int, s *goquery.Selection, pageURL string) (*Schedule, error) {
 	cinema, err := parseCinema(s)
 	if err != nil {
-		// TODO: proper error handling
-		fmt.Println(err)
 		return nil, err
 	}
 	cinema.ID = c
 
-	result := &Schedule{}
+	result := &Schedule{page: pageURL}
 
-	var disclaimer = &NoteMap{
-		m: make(map[string]Note),
-	}
 	var loc *time.Location
 	// Try to retrieve time zone for cinema
 	{
-		e := s.Find("div:nth-child(1) > p")
+		e := s.Find(selScheduleState)
 		t := strings.TrimSpace(strings.Replace(e.Text(), "por cinemas em", "", -1))
 		if t != "" { // Expected state name
-			tz := getTimeZone(t)
+			tz := getCityTimeZone(t, cinema.Address.City)
 			cinema.TimeZone = tz
 
-			loc, _ = time.LoadLocation(tz)
+			loc = util.LoadLocation(tz)
 			result.loc = loc
-			result.dis = disclaimer
 		}
 	}
 	result.Cinema = cinema
 
 	s.Find("div").EachWithBreak(func(i int, s *goquery.Selection) bool {
 		if class := s.AttrOr("class", ""); strings.HasPrefix(class, "cinema") {
-			var err error
-
-			dates := s.Find("h4 > strong")
+			dates := s.Find(selPeriod)
 			if dates.Length() == 2 {
-				start, _ := util.StringToTime(util.GetText("", dates.First()), "/", loc)
-				end, _ := util.StringToTime(util.GetText("", dates.Last()), "/", loc)
-				if start != nil && end != nil {
+				// Dates may lack the year, which is the closest one to today
+				today := result.periodStart()
+				start, okStart := parseNoteDate(util.GetText("", dates.First()), today)
+				end, okEnd := parseNoteDate(util.GetText("", dates.Last()), today)
+				if okStart && okEnd {
+					// Periods crossing the New Year as in 29/12 to 04/01
+					if end.Before(start) {
+						end = end.AddDate(1, 0, 0)
+					}
 					result.Period = &Period{
-						Start: *start,
-						End:   *end,
+						Start: start,
+						End:   end,
 					}
 				} else {
-					err = errors.New("couldn't retrieve schedule period")
+					err = &ParseError{URL: pageURL, Selector: selPeriod, Text: util.GetText("", dates), Err: ErrLayoutChanged}
 				}
 			} else {
-				err = errors.New("unexpected DOM structure")
+				err = &ParseError{URL: pageURL, Selector: selPeriod, Err: ErrLayoutChanged}
 			}
 
 			if err != nil {
-				// TODO: proper error handling
-				log.Fatal(err)
+				return false
 			}
-			result.fillDisclaimer(s.Find("span.hleter"))
-			s.Find("div.filme").Each(func(i int, s *goquery.Selection) {
+			result.fillNotes(s.Find(selNotes))
+			s.Find(selMovieSessions).Each(func(i int, s *goquery.Selection) {
 				sessions, err := parseSessions(s, result)
 				if err != nil {
-					// TODO: proper error handling
-					log.Fatal(err)
+					result.Diagnostics.add(pageURL, selMovieSessions, util.GetText(selSessionsMovie, s), err)
+					return
 				}
 
 				result.Sessions = append(result.Sessions, sessions...)
@@ -253,19 +273,23 @@ func parseSchedule(c int, s *goquery.Selection) (*Schedule, error) {
 		return true
 	})
 
-	return result, err
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func parseSessions(s *goquery.Selection, sched *Schedule) ([]Session, error) {
 	var result []Session
 
-	a := s.Find("h2 > a")
+	a := s.Find(selSessionsMovie)
 	t := strings.TrimSpace(a.Text())
 	if t == "" {
-		return nil, errors.New("couldn't find movie title")
+		return nil, fmt.Errorf("couldn't find movie title: %w", ErrLayoutChanged)
 	}
 
-	var idMovie, room int
+	var idMovie int
 	var title string
 
 	idMovie, err := movieutil.IDFromURLString(a.AttrOr("href", ""))
@@ -276,26 +300,13 @@ func parseSessions(s *goquery.Selection, sched *Schedule) ([]Session, error) {
 
 	title = t
 
-	remainder := strings.TrimSpace(s.Find("h2.salas").Text())
-	if remainder != "" {
-		lhs, rhs := util.BreakBySpaces(remainder)
-		if lhs == "Sala" {
-			num, rhs := util.BreakBySpaces(rhs)
-			if num != "" {
-				value, err := strconv.Atoi(num)
-				if err != nil {
-					fmt.Printf("couldn't convert room number %s to integer", num)
-					return nil, err
-				}
-				room = value
-				remainder = rhs
-			}
-		}
-	}
-
-	if room == 0 {
-		// Skip
-		return nil, err
+	rooms := strings.TrimSpace(s.Find(selSessionsRooms).Text())
+	room, remainder, unknown := parseRoom(rooms)
+	if room.Label == "" {
+		// Keep the sessions, even though their room is unknown
+		sched.Diagnostics.add(sched.page, selSessionsRooms, rooms, fmt.Errorf("couldn't find room: %w", ErrLayoutChanged))
+	} else if len(unknown) > 0 {
+		sched.Diagnostics.add(sched.page, selSessionsRooms, room.Label, fmt.Errorf("unknown words %q in room: %w", unknown, ErrLayoutChanged))
 	}
 
 	session := Session{
@@ -305,12 +316,18 @@ func parseSessions(s *goquery.Selection, sched *Schedule) ([]Session, error) {
 		Room:       room,
 		Format:     Format2D,
 	}
+	if f, ok := roomFeatures[room.Kind]; ok {
+		session.Features.Add(f)
+	}
 
-	s.Find("div.icons div").Each(func(i int, s *goquery.Selection) {
-		hint := s.AttrOr("data-hint", "")
-		if hint != "" {
-			hint = strings.ToLower(hint)
-			if strings.Contains(hint, "dub") {
+	s.Find(selSessionsIcons).Each(func(i int, s *goquery.Selection) {
+		original := strings.TrimSpace(s.AttrOr("data-hint", ""))
+		if original != "" {
+			hint := strings.ToLower(original)
+			// Features first, since "Legenda descritiva" isn't a version
+			if f, ok := parseFeature(original); ok {
+				session.Features.Add(f)
+			} else if strings.Contains(hint, "dub") {
 				session.Version = VersionDubbed
 			} else if strings.Contains(hint, "leg") {
 				session.Version = VersionSubtitled
@@ -320,12 +337,10 @@ func parseSessions(s *goquery.Selection, sched *Schedule) ([]Session, error) {
 				session.Format = Format3D
 			} else if strings.Contains(hint, "4dx") {
 				session.Format = Format4DX
-			} else if strings.Contains(hint, "vip") {
-				session.VIP = true
-			} else if strings.Contains(hint, "xd") {
-				session.XD = true
-			} else if strings.Contains(hint, "imax") {
-				session.IMAX = true
+			} else {
+				// Keep the hint as is, so it isn't lost
+				session.Features.Add(Feature(original))
+				sched.Diagnostics.add(sched.page, selSessionsIcons, hint, fmt.Errorf("unknown icon hint: %w", ErrLayoutChanged))
 			}
 		}
 	})
@@ -337,80 +352,57 @@ func parseSessions(s *goquery.Selection, sched *Schedule) ([]Session, error) {
 			break
 		}
 
-		var letter string
-		var size = len(ot)
-		// If time has a letter as last character remove it and update time string
-		if size > 0 && unicode.IsLetter(rune(ot[size-1])) {
-			letter = ot[size-1:]
-			ot = ot[0 : size-1]
-		}
+		// Times may end with the letters of the notes applying to them
+		clock, letters := splitNoteLetters(ot)
 
-		h, m := util.BreakByToken(ot, 'h')
-		if h == "" || m == "" {
-			err = errors.New("couldn't find time")
+		// Skip times that can't be parsed, keeping the other ones
+		h, m := util.BreakByToken(clock, 'h')
+		hours, errHours := strconv.Atoi(h)
+		minutes, errMinutes := strconv.Atoi(m)
+		if errHours != nil || errMinutes != nil {
+			sched.Diagnostics.add(sched.page, selSessionsRooms, ot, fmt.Errorf("couldn't parse session time: %w", ErrLayoutChanged))
+			continue
 		}
 
-		if err != nil {
-			// TODO: Proper error handling
-			return nil, err
+		s := session
+		// Note features are of this time only
+		s.Features = append(Features(nil), session.Features...)
+		var notes []Note
+		for _, letter := range letters {
+			n, ok := sched.Note(letter)
+			if !ok {
+				// Keep the session as if the note didn't exist
+				sched.Diagnostics.add(sched.page, selSessionsRooms, ot, fmt.Errorf("unknown note %s: %w", letter, ErrLayoutChanged))
+				continue
+			}
+			notes = append(notes, n)
+			s.Notes += letter
+			s.PreSale = s.PreSale || n.PreSale
+			s.Premiere = s.Premiere || n.Premiere
+			for _, f := range n.Features {
+				s.Features.Add(f)
+			}
 		}
 
-		hours, err := strconv.Atoi(h)
-		if err != nil {
-			return nil, err
+		for _, day := range sched.sessionDays(notes) {
+			s.StartTime = setTime(&day, hours, minutes)
+			result = append(result, s)
 		}
+	}
 
-		minutes, err := strconv.Atoi(m)
-		if err != nil {
-			return nil, err
-		}
+	return result, nil
+}
 
-		if letter != "" {
-			sched.dis.RLock()
-			n, ok := sched.dis.m[letter]
-			sched.dis.RUnlock()
-			if ok {
-				s := session
-				if n.Type == NoteOnlyDayX {
-					for _, day := range n.Days {
-						y, m, d := day.Date()
-						st := time.Date(y, m, d, hours, minutes, 0, 0, sched.loc)
-						s.StartTime = &st
-						result = append(result, s)
-					}
-				} else {
-					for d := 0; d < 7; d++ {
-						nd := sched.Period.Start.AddDate(0, 0, d)
-						add := true
-						for _, day := range n.Days {
-							// NOTE: Only day and month are enough here because we use
-							// AddDate above which guarantees the correctness of time
-							// when we deal with dates near New Year's Eve
-							_, m, d := day.Date()
-							if nd.Day() == d && nd.Month() == m {
-								add = false
-								break
-							}
-						}
-
-						if add {
-							s.StartTime = setTime(&nd, hours, minutes)
-							result = append(result, s)
-						}
-					}
-				}
-			} else {
-				// TODO: Proper error handling
-			}
-		} else {
-			// Build sessions for the whole week
-			for d := 0; d < 7; d++ {
-				nd := sched.Period.Start.AddDate(0, 0, d)
-				session.StartTime = setTime(&nd, hours, minutes)
-				result = append(result, session)
-			}
-		}
+// ID identifies the session by its cinema, movie, room, start time,
+// format and version, as a 16 characters hexadecimal string. It is stable
+// across fetches of the schedule, so it changes only when one of these
+// does.
+func (s Session) ID() string {
+	var start int64
+	if s.StartTime != nil {
+		start = s.StartTime.Unix()
 	}
-
-	return result, err
+	key := fmt.Sprintf("%d|%d|%s|%d|%s|%s", s.CinemaID, s.MovieID, s.Room.Slug(), start, s.Format, s.Version)
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
 }
@@ -1,11 +1,15 @@
 package claquete
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetSchedule(t *testing.T) {
-	sched, err := GetSchedule(656) // Cinemais Montes Claros
+	sched, err := GetSchedule(656, fixtures(t)) // Cinemais Montes Claros
 	if err != nil {
 		t.Fatalf("expected no error, but got error: %s", err.Error())
 	}
@@ -14,3 +18,211 @@ func TestGetSchedule(t *testing.T) {
 		t.Fatalf("expected Cinemais Montes Claros, got %s", sched.Cinema.Name)
 	}
 }
+
+func TestParseSchedule(t *testing.T) {
+	f, err := os.Open("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sched, err := ParseSchedule(f, "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if sched.Cinema.ID != 656 {
+		t.Fatalf("expected cinema 656, got %d", sched.Cinema.ID)
+	}
+
+	if sched.Cinema.Name != "Cinemais Montes Claros" {
+		t.Fatalf("expected Cinemais Montes Claros, got %s", sched.Cinema.Name)
+	}
+
+	if sched.Cinema.TimeZone != "America/Sao_Paulo" {
+		t.Fatalf("expected America/Sao_Paulo, got %s", sched.Cinema.TimeZone)
+	}
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	expectedStart := time.Date(2019, time.May, 16, 0, 0, 0, 0, loc)
+	if sched.Period == nil || !sched.Period.Start.Equal(expectedStart) {
+		t.Fatalf("expected period starting at %s, got %+v", expectedStart, sched.Period)
+	}
+
+	// 7 sessions at 14h00, 2 at 17h30 (A), 6 at 21h00 (B) and 7 at 20h15
+	expectedSize := 22
+	if len(sched.Sessions) != expectedSize {
+		t.Fatalf("expected %d sessions, got %d", expectedSize, len(sched.Sessions))
+	}
+
+	first := sched.Sessions[0]
+	if first.MovieID != 8427 || first.Room.Number != 1 || first.Format != Format3D || first.Version != VersionDubbed {
+		t.Fatalf("unexpected session %+v", first)
+	}
+
+	last := sched.Sessions[len(sched.Sessions)-1]
+	if last.MovieID != 8600 || last.Room.Number != 2 || !last.Features.Has(FeatureVIP) || last.Version != VersionSubtitled {
+		t.Fatalf("unexpected session %+v", last)
+	}
+
+	expectedTime := time.Date(2019, time.May, 22, 20, 15, 0, 0, loc)
+	if !last.StartTime.Equal(expectedTime) {
+		t.Fatalf("expected %s, got %s", expectedTime, last.StartTime)
+	}
+}
+
+func TestParseScheduleDiagnostics(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		"Sala 2 20h15", "Sala 2 20h15, 2xh00",
+		`data-hint="Sala VIP"`, `data-hint="Holograma"`,
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	expectedSize := 22
+	if len(sched.Sessions) != expectedSize {
+		t.Fatalf("expected %d sessions, got %d", expectedSize, len(sched.Sessions))
+	}
+
+	expected := []string{"holograma", "2xh00"}
+	if len(sched.Diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), sched.Diagnostics)
+	}
+	for i, d := range sched.Diagnostics {
+		if d.Text != expected[i] {
+			t.Fatalf("expected %s, got %s", expected[i], d.Text)
+		}
+	}
+}
+
+func TestParseSchedulePeriod(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	cases := []struct {
+		name         string
+		start, end   string
+		a, b         string
+		expectedSize int
+		expectedA    time.Time
+	}{
+		// 4 sessions at 14h00, 2 at 17h30 (A), 4 at 21h00 (B) and 4 at 20h15
+		{"short", "16/05/2019", "19/05/2019", "Somente Sáb. (18/05) Dom. (19/05)", "Exceto Qua. (22/05)", 14, time.Date(2019, time.May, 18, 17, 30, 0, 0, loc)},
+		// 8 sessions at 14h00, 2 at 17h30 (A), 7 at 21h00 (B) and 8 at 20h15
+		{"long", "16/05/2019", "23/05/2019", "Somente Sáb. (18/05) Dom. (19/05)", "Exceto Qua. (22/05)", 25, time.Date(2019, time.May, 18, 17, 30, 0, 0, loc)},
+		// 7 sessions at 14h00, 1 at 17h30 (A), 6 at 21h00 (B) and 7 at 20h15
+		{"new year", "28/12/2019", "03/01/2020", "Somente Qua. (01/01)", "Exceto Ter. (31/12)", 21, time.Date(2020, time.January, 1, 17, 30, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		page := strings.NewReplacer(
+			"16/05/2019", c.start,
+			"22/05/2019", c.end,
+			"Somente Sáb. (18/05) Dom. (19/05)", c.a,
+			"Exceto Qua. (22/05)", c.b,
+		).Replace(string(b))
+
+		sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got error: %s", c.name, err.Error())
+		}
+		if len(sched.Sessions) != c.expectedSize {
+			t.Fatalf("%s: expected %d sessions, got %d", c.name, c.expectedSize, len(sched.Sessions))
+		}
+
+		var first *time.Time
+		for _, s := range sched.Sessions {
+			if s.StartTime.Before(sched.Period.Start) || s.StartTime.After(sched.Period.End.AddDate(0, 0, 1)) {
+				t.Fatalf("%s: session %s out of period", c.name, s.StartTime)
+			}
+			if s.Notes == "A" && first == nil {
+				first = s.StartTime
+			}
+		}
+		if first == nil || !first.Equal(c.expectedA) {
+			t.Fatalf("%s: expected %s, got %v", c.name, c.expectedA, first)
+		}
+	}
+}
+
+func TestParseSchedulePeriodWithoutYear(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		"16/05/2019", "29/12",
+		"22/05/2019", "04/01",
+		"Somente Sáb. (18/05) Dom. (19/05)", "Somente Qua. (01/01)",
+		"Exceto Qua. (22/05)", "Exceto Ter. (31/12)",
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	start := sched.Period.Start
+	if start.Month() != time.December || start.Day() != 29 || !sched.Period.End.Equal(start.AddDate(0, 0, 6)) {
+		t.Fatalf("expected period from 29/12 to 04/01 of the next year, got %+v", sched.Period)
+	}
+
+	// 7 sessions at 14h00, 1 at 17h30 (A), 6 at 21h00 (B) and 7 at 20h15
+	if len(sched.Sessions) != 21 {
+		t.Fatalf("expected 21 sessions, got %d", len(sched.Sessions))
+	}
+	for _, s := range sched.Sessions {
+		if s.Notes == "A" && (s.StartTime.Year() != start.Year()+1 || s.StartTime.YearDay() != 1) {
+			t.Fatalf("expected session A on 01/01/%d, got %s", start.Year()+1, s.StartTime)
+		}
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func() *Schedule {
+		sched, err := ParseSchedule(strings.NewReader(string(b)), "http://claquete.com.br/programacao/656/cinema-656.html")
+		if err != nil {
+			t.Fatalf("expected no error, but got error: %s", err.Error())
+		}
+		return sched
+	}
+	first, second := parse(), parse()
+
+	ids := make(map[string]bool)
+	for i, s := range first.Sessions {
+		id := s.ID()
+		if len(id) != 16 || ids[id] {
+			t.Fatalf("expected unique ID, got %s", id)
+		}
+		ids[id] = true
+		if other := second.Sessions[i].ID(); other != id {
+			t.Fatalf("expected %s on re-fetch, got %s", id, other)
+		}
+	}
+
+	s := first.Sessions[0]
+	utc := s.StartTime.UTC()
+	moved := s
+	moved.StartTime = &utc
+	if moved.ID() != s.ID() {
+		t.Fatal("expected ID not to depend on time zone")
+	}
+	moved.Room = Room{Label: "Sala 3", Number: 3}
+	if moved.ID() == s.ID() {
+		t.Fatal("expected ID to change with room")
+	}
+}
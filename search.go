@@ -1,8 +1,10 @@
 package claquete
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"regexp"
 	"strconv"
@@ -74,6 +76,8 @@ type (
 		Filtered   bool           `json:"filtered"`
 		TotalCount int            `json:"total_count"`
 		Results    []SearchResult `json:"results"`
+		// Diagnostics lists the dates and years that couldn't be parsed.
+		Diagnostics ParseErrors `json:"diagnostics,omitempty"`
 	}
 
 	// SearchResult represents one row returned from search operation.
@@ -103,102 +107,88 @@ func (s *SearchResults) ShouldIncludeType(t string) bool {
 }
 
 // Search performs a search operation without filtering results.
-func Search(query string) (*SearchResults, error) {
-	return search(query, DefaultSearchFilterFlags)
+func Search(query string, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(context.Background(), query, DefaultSearchFilterFlags, options...)
+}
+
+// SearchContext is like Search but aborts when ctx is done.
+func SearchContext(ctx context.Context, query string, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(ctx, query, DefaultSearchFilterFlags, options...)
 }
 
 // SearchCinemas performs a search operation filtering results to Cinema only.
-func SearchCinemas(query string) (*SearchResults, error) {
-	return search(query, SearchFilterCinema)
+func SearchCinemas(query string, options ...Options) (*SearchResults, error) {
+	return SearchCinemasContext(context.Background(), query, options...)
+}
+
+// SearchCinemasContext is like SearchCinemas but aborts when ctx is done.
+func SearchCinemasContext(ctx context.Context, query string, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(ctx, query, SearchFilterCinema, options...)
 }
 
 // SearchMovies performs a search operation filtering results to Movie only.
-func SearchMovies(query string) (*SearchResults, error) {
-	return search(query, SearchFilterMovie)
+func SearchMovies(query string, options ...Options) (*SearchResults, error) {
+	return SearchMoviesContext(context.Background(), query, options...)
+}
+
+// SearchMoviesContext is like SearchMovies but aborts when ctx is done.
+func SearchMoviesContext(ctx context.Context, query string, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(ctx, query, SearchFilterMovie, options...)
 }
 
 // SearchNews performs a search operation filtering results to News only.
-func SearchNews(query string) (*SearchResults, error) {
-	return search(query, SearchFilterNews)
+func SearchNews(query string, options ...Options) (*SearchResults, error) {
+	return SearchNewsContext(context.Background(), query, options...)
+}
+
+// SearchNewsContext is like SearchNews but aborts when ctx is done.
+func SearchNewsContext(ctx context.Context, query string, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(ctx, query, SearchFilterNews, options...)
 }
 
 // SearchFiltered performs a search operation and applies a filter to its results.
 // filterFlags is used to specify what it should return.
-func SearchFiltered(query string, filterFlags SearchFilterFlag) (*SearchResults, error) {
-	return search(query, filterFlags)
+func SearchFiltered(query string, filterFlags SearchFilterFlag, options ...Options) (*SearchResults, error) {
+	return SearchFilteredContext(context.Background(), query, filterFlags, options...)
 }
 
-// Search searches in Claquete's website.
-func search(query string, filterFlags SearchFilterFlag) (*SearchResults, error) {
-	if len(query) < MinQueryLength {
-		return nil, errors.New("query length must be at least 2")
+// SearchFilteredContext is like SearchFiltered but aborts when ctx is done.
+func SearchFilteredContext(ctx context.Context, query string, filterFlags SearchFilterFlag, options ...Options) (*SearchResults, error) {
+	return NewClaquete(options...).SearchFilteredContext(ctx, query, filterFlags)
+}
+
+// Search performs a search operation without filtering results.
+func (c *Claquete) Search(query string) (*SearchResults, error) {
+	return c.SearchFilteredContext(context.Background(), query, DefaultSearchFilterFlags)
+}
+
+// SearchContext is like Search but aborts when ctx is done.
+func (c *Claquete) SearchContext(ctx context.Context, query string) (*SearchResults, error) {
+	return c.SearchFilteredContext(ctx, query, DefaultSearchFilterFlags)
+}
+
+// SearchFiltered performs a search operation and applies a filter to its results.
+// filterFlags is used to specify what it should return.
+func (c *Claquete) SearchFiltered(query string, filterFlags SearchFilterFlag) (*SearchResults, error) {
+	return c.SearchFilteredContext(context.Background(), query, filterFlags)
+}
+
+// SearchFilteredContext searches in Claquete's website and aborts when ctx is done.
+func (c *Claquete) SearchFilteredContext(ctx context.Context, query string, filterFlags SearchFilterFlag) (*SearchResults, error) {
+	if c.err != nil {
+		return nil, c.err
 	}
 
-	result := &SearchResults{
-		FilterFlags: filterFlags,
-		Query:       query,
+	if len(query) < MinQueryLength {
+		return nil, fmt.Errorf("query length must be at least %d: %w", MinQueryLength, ErrInvalidArgument)
 	}
 
-	c := NewClaquete()
-	c.collector.OnHTML("#busca_ajax", func(e *colly.HTMLElement) {
-		totalCountRE := regexp.MustCompile("(\\d+)\\sresultados")
-		var sr SearchResult
-		e.DOM.Children().Each(func(i int, s *goquery.Selection) {
-			// Parse found results count
-			if s.Is("h3") && result.TotalCount == 0 {
-				r := totalCountRE.FindStringSubmatch(strings.ToLower(s.Text()))
-				if len(r) == 2 {
-					totalCount, err := strconv.Atoi(r[1])
-					if err != nil {
-						fmt.Printf("couldn't find total count in text: %s\n", r[1])
-					} else {
-						result.TotalCount = totalCount
-					}
-				} else {
-					fmt.Printf("expected size 2, got: %d\n", len(r))
-				}
-			} else if s.Is("div") && s.AttrOr("class", "") == "ttsubn" {
-				t := getSearchType(s.Text())
-				if result.ShouldIncludeType(t) {
-					sr = SearchResult{
-						Type: getSearchType(s.Text()),
-					}
-				}
-			} else if s.Is("span") && sr.Type != "" {
-				// If is a span and we have a result, parse the date/year information
-				str := strings.TrimSpace(s.Text())
-				if sr.Type == SearchTypeNews {
-					d, _, err := util.CreateDate(str, " de ")
-					if err != nil {
-						// TODO: proper error handling
-						fmt.Println(err)
-					} else {
-						sr.Date = d
-					}
-				} else if sr.Type == SearchTypeMovie && sr.Type != "" {
-					year, err := strconv.Atoi(str)
-					if err != nil {
-						// TODO: proper error handling
-						fmt.Printf("couldn't convert text %s to year\n", str)
-					} else {
-						sr.Year = year
-					}
-				} else if sr.Type == SearchTypeCinema && sr.Type != "" {
-					// Do nothing.
-				}
-			} else if s.Is("h2") && sr.Type != "" {
-				// If is a h2 and we have a result, get title and page information
-				sr.Title = s.Text()
-				sr.Page = s.Find("a").AttrOr("href", "")
-				result.Results = append(result.Results, sr)
-			}
-		})
+	var result *SearchResults
+	var err error
 
-		// Update totalCount to match filtered results
-		if result.FilterFlags != DefaultSearchFilterFlags {
-			result.TotalCount = len(result.Results)
-			result.Filtered = true
-		}
+	collector := c.collect(ctx)
+	collector.OnResponse(func(r *colly.Response) {
+		result, err = ParseSearchResults(bytes.NewReader(r.Body), query, filterFlags)
 	})
 
 	// NOTE(diego):
@@ -208,14 +198,99 @@ func search(query string, filterFlags SearchFilterFlag) (*SearchResults, error)
 	// Request works without them, but let's send it anyway.
 	searchBtnWidth := 30
 	searchBtnHeight := 31
-	err := c.collector.Post(BaseURL+"/busca.html", map[string]string{
+	errPost := collector.Post(c.url("/busca.html"), map[string]string{
 		"query": query,
 		"x":     strconv.Itoa(rand.Intn(searchBtnWidth)),
 		"y":     strconv.Itoa(rand.Intn(searchBtnHeight)),
 	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errPost != nil {
+		return nil, errPost
+	}
 	return result, err
 }
 
+// ParseSearchResults parses a search results page read from r. The page
+// is the response to a POST with the given query and doesn't carry it,
+// so query and filterFlags must be the ones used in the search.
+//
+// Dates and years that couldn't be parsed are left empty and reported in
+// the results Diagnostics.
+func ParseSearchResults(r io.Reader, query string, filterFlags SearchFilterFlag) (*SearchResults, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResults{
+		FilterFlags: filterFlags,
+		Query:       query,
+	}
+
+	totalCountRE := regexp.MustCompile("(\\d+)\\sresultados")
+	var sr SearchResult
+	doc.Find(selSearch).Children().Each(func(i int, s *goquery.Selection) {
+		// Parse found results count
+		if s.Is("h3") && result.TotalCount == 0 {
+			r := totalCountRE.FindStringSubmatch(strings.ToLower(s.Text()))
+			if len(r) == 2 {
+				totalCount, err := strconv.Atoi(r[1])
+				if err != nil {
+					result.Diagnostics.add("", selSearch+" > h3", r[1], err)
+				} else {
+					result.TotalCount = totalCount
+				}
+			} else {
+				result.Diagnostics.add("", selSearch+" > h3", s.Text(), ErrLayoutChanged)
+			}
+		} else if s.Is("div") && s.AttrOr("class", "") == "ttsubn" {
+			t := getSearchType(s.Text())
+			if result.ShouldIncludeType(t) {
+				sr = SearchResult{
+					Type: t,
+				}
+			} else {
+				sr = SearchResult{}
+			}
+		} else if s.Is("span") && sr.Type != "" {
+			// If is a span and we have a result, parse the date/year information
+			str := strings.TrimSpace(s.Text())
+			if sr.Type == SearchTypeNews {
+				d, _, err := util.CreateDate(str, " de ")
+				if err != nil {
+					result.Diagnostics.add("", selSearch+" > span", str, err)
+				} else {
+					sr.Date = d
+				}
+			} else if sr.Type == SearchTypeMovie && sr.Type != "" {
+				year, err := strconv.Atoi(str)
+				if err != nil {
+					result.Diagnostics.add("", selSearch+" > span", str, err)
+				} else {
+					sr.Year = year
+				}
+			} else if sr.Type == SearchTypeCinema && sr.Type != "" {
+				// Do nothing.
+			}
+		} else if s.Is("h2") && sr.Type != "" {
+			// If is a h2 and we have a result, get title and page information
+			sr.Title = s.Text()
+			sr.Page = s.Find("a").AttrOr("href", "")
+			result.Results = append(result.Results, sr)
+		}
+	})
+
+	// Update totalCount to match filtered results
+	if result.FilterFlags != DefaultSearchFilterFlags {
+		result.TotalCount = len(result.Results)
+		result.Filtered = true
+	}
+
+	return result, nil
+}
+
 // getSearchType retrieves the search type for a given string.
 func getSearchType(str string) string {
 	result := ""
@@ -7,7 +7,7 @@ import (
 )
 
 func TestSearch(t *testing.T) {
-	results, err := Search("cinemais")
+	results, err := Search("cinemais", fixtures(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -19,7 +19,7 @@ func TestSearch(t *testing.T) {
 
 func TestSearchFiltered(t *testing.T) {
 	// Retrieves all Avengers movies.
-	results, err := SearchFiltered("vingadores", SearchFilterMovie)
+	results, err := SearchFiltered("vingadores", SearchFilterMovie, fixtures(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -32,7 +32,7 @@ func TestSearchFiltered(t *testing.T) {
 
 	// This is retrieving results of all three types (Cinema, Movie, News)
 	// 21 dec. 2018
-	results, err = SearchFiltered("são paulo", SearchFilterMovie|SearchFilterNews)
+	results, err = SearchFiltered("são paulo", SearchFilterMovie|SearchFilterNews, fixtures(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -42,3 +42,30 @@ func TestSearchFiltered(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSearchResults(t *testing.T) {
+	f, err := os.Open("testdata/busca.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	results, err := ParseSearchResults(f, "vingadores", SearchFilterMovie|SearchFilterNews)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.Results) != 2 || results.TotalCount != 2 || !results.Filtered {
+		t.Fatalf("expected 2 filtered results, got %+v", results)
+	}
+
+	for _, r := range results.Results {
+		if r.Type != SearchTypeMovie && r.Type != SearchTypeNews {
+			t.Fatal("expected all results to be filtered to movie and news only")
+		}
+	}
+
+	if results.Results[0].Year != 2019 {
+		t.Fatalf("expected year 2019, got %d", results.Results[0].Year)
+	}
+}
@@ -0,0 +1,60 @@
+package claquete
+
+import "fmt"
+
+// Selectors of the website elements read by the parsers. The health probes
+// check these same selectors, so a page failing a probe is a page the
+// parsers can't read. Selectors of elements read within other elements are
+// relative to them, as noted.
+const (
+	// States and cities, in the index page
+	selStates = "#selUf > option"
+	selCities = "#cidade > option"
+
+	// Schedule page
+	selSchedule      = "body > div.conteudo > div.progrb"
+	selScheduleState = "div:nth-child(1) > p" // in selSchedule
+	selCinemaName    = "div > div > div.ttcine > h2"
+	selCinemaAddress = "div > div > span"
+	selPeriod        = "h4 > strong"   // in the cinema element
+	selNotes         = "span.hleter"   // in the cinema element
+	selMovieSessions = "div.filme"     // in the cinema element
+	selSessionsMovie = "h2 > a"        // in selMovieSessions
+	selSessionsRooms = "h2.salas"      // in selMovieSessions
+	selSessionsIcons = "div.icons div" // in selMovieSessions
+
+	// Movie page
+	selMovieRating = "div.mvposter > div.mvclassif img"
+	selMovieDesc   = "div.mvdesc"
+	selMovieTitle  = "h1" // in selMovieDesc
+	selMovieOrig   = "h2" // in selMovieDesc
+	selMovieInfo   = "p"  // in selMovieDesc
+	selMovieCast   = "#cont1"
+	selMovieImages = "#cont2 img"
+
+	// News pages
+	selNews          = "body > div.conteudo > div.noticias"
+	selHeadlineImage = "a > div.principal" // in selNews
+	selHeadlineDate  = "span"              // child of selNews
+	selHeadlineTitle = "h2"                // child of selNews
+	selNewsDate      = "span:nth-child(1)" // in selNews
+	selNewsAuthor    = "span:nth-child(4)" // in selNews
+	selNewsTitle     = "h1"                // in selNews
+	selNewsContent   = "p"                 // in selNews
+	selReleases      = "#carrossel > ul:nth-child(1) > li"
+
+	// Search page
+	selSearch = "#busca_ajax"
+
+	// Calendar
+	selCalendarWeek     = "div.cxsem"
+	selCalendarDay      = "p" // in selCalendarWeek
+	selCalendarPosters  = "ul.posters"
+	selCalendarSynopsis = `div[data-hint="Sinopse"]` // in selCalendarPosters
+)
+
+// selScheduleCinema returns the selector of the element holding the
+// schedule of the cinema with the given ID.
+func selScheduleCinema(id int) string {
+	return fmt.Sprintf("%s > div.cinema%d", selSchedule, id)
+}
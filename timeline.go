@@ -0,0 +1,192 @@
+package claquete
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DefaultSessionBuffer is the time taken by trailers before a movie and
+// by cleaning the room after it, used when SessionBuffer isn't set.
+const DefaultSessionBuffer = 20 * time.Minute
+
+type (
+	// RoomTimeline is the sessions of a room in the order they start.
+	RoomTimeline struct {
+		CinemaID int       `json:"cinema_id"`
+		Room     Room      `json:"room_info"`
+		Sessions []Session `json:"sessions"`
+	}
+
+	// Overlap is a pair of sessions using the same room at the same time,
+	// which means either the cinema double-booked the room or the page
+	// wasn't parsed correctly.
+	Overlap struct {
+		CinemaID int     `json:"cinema_id"`
+		Room     Room    `json:"room_info"`
+		First    Session `json:"first"`
+		Second   Session `json:"second"`
+	}
+)
+
+// SessionBuffer sets the time added to the movie runtime by SetEndTimes
+// to account for trailers and cleaning the room.
+func SessionBuffer(d time.Duration) func(*Claquete) {
+	return func(c *Claquete) {
+		c.sessionBuffer = d
+	}
+}
+
+// EndAt returns when the session ends given the movie runtime in minutes
+// and the time taken by trailers and cleaning, or nil when either the
+// start or the runtime is unknown.
+func (s Session) EndAt(runtime int, buffer time.Duration) *time.Time {
+	if s.StartTime == nil || runtime <= 0 {
+		return nil
+	}
+	end := s.StartTime.Add(time.Duration(runtime)*time.Minute + buffer)
+	return &end
+}
+
+// SetEndTimes sets the EndTime of the sessions whose movie is in runtimes,
+// which maps movie IDs to their runtime in minutes.
+func (sched *Schedule) SetEndTimes(runtimes map[int]int, buffer time.Duration) {
+	for i := range sched.Sessions {
+		s := &sched.Sessions[i]
+		s.EndTime = s.EndAt(runtimes[s.MovieID], buffer)
+	}
+}
+
+// SetEndTimes retrieves the movies of sched to set the EndTime of its
+// sessions. Sessions of movies that couldn't be retrieved or have no
+// runtime are left without one.
+func (c *Claquete) SetEndTimes(sched *Schedule) error {
+	return c.SetEndTimesContext(context.Background(), sched)
+}
+
+// SetEndTimesContext is like SetEndTimes but aborts when ctx is done.
+func (c *Claquete) SetEndTimesContext(ctx context.Context, sched *Schedule) error {
+	var ids []int
+	for _, s := range sched.Sessions {
+		ids = append(ids, s.MovieID)
+	}
+
+	movies, err := c.GetMoviesContext(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	runtimes := make(map[int]int)
+	for _, r := range movies {
+		if r.Movie != nil {
+			runtimes[r.ID] = r.Movie.Runtime
+		}
+	}
+	sched.SetEndTimes(runtimes, c.sessionBuffer)
+
+	return nil
+}
+
+// MarshalJSON encodes the timeline with the room number in the room field,
+// as in previous versions.
+func (rt RoomTimeline) MarshalJSON() ([]byte, error) {
+	type timeline RoomTimeline
+	return json.Marshal(struct {
+		timeline
+		Room int `json:"room"`
+	}{timeline(rt), rt.Room.Number})
+}
+
+// MarshalJSON encodes the overlap with the room number in the room field,
+// as in previous versions.
+func (o Overlap) MarshalJSON() ([]byte, error) {
+	type overlap Overlap
+	return json.Marshal(struct {
+		overlap
+		Room int `json:"room"`
+	}{overlap(o), o.Room.Number})
+}
+
+// Timeline returns the sessions of each room, ordered by cinema and room.
+// Sessions without start time are left out.
+func (sched *Schedule) Timeline() []RoomTimeline {
+	type key struct {
+		cinema int
+		room   Room
+	}
+
+	index := make(map[key]int)
+	var result []RoomTimeline
+	for _, s := range sched.Sessions {
+		if s.StartTime == nil {
+			continue
+		}
+		k := key{s.CinemaID, s.Room}
+		i, ok := index[k]
+		if !ok {
+			i = len(result)
+			index[k] = i
+			result = append(result, RoomTimeline{CinemaID: s.CinemaID, Room: s.Room})
+		}
+		result[i].Sessions = append(result[i].Sessions, s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CinemaID != result[j].CinemaID {
+			return result[i].CinemaID < result[j].CinemaID
+		}
+		return compareRooms(result[i].Room, result[j].Room) < 0
+	})
+	for _, t := range result {
+		sessions := t.Sessions
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].StartTime.Before(*sessions[j].StartTime)
+		})
+	}
+
+	return result
+}
+
+// Overlaps returns the sessions that start before the previous one in the
+// same room ends. Sessions without EndTime only overlap others starting at
+// the same time.
+func (sched *Schedule) Overlaps() []Overlap {
+	var result []Overlap
+	for _, t := range sched.Timeline() {
+		// Latest ending session seen so far, which may not be the previous
+		// one when a long session overlaps several short ones.
+		var last *Session
+		for i := range t.Sessions {
+			s := &t.Sessions[i]
+			if last != nil && overlaps(*last, *s) {
+				result = append(result, Overlap{
+					CinemaID: t.CinemaID,
+					Room:     t.Room,
+					First:    *last,
+					Second:   *s,
+				})
+			}
+			if last == nil || sessionEnd(*s).After(sessionEnd(*last)) {
+				last = s
+			}
+		}
+	}
+	return result
+}
+
+// overlaps reports whether b, starting after a, starts before a ends.
+func overlaps(a, b Session) bool {
+	if b.StartTime.Equal(*a.StartTime) {
+		return true
+	}
+	return b.StartTime.Before(sessionEnd(a))
+}
+
+// sessionEnd returns when s ends, or its start when unknown.
+func sessionEnd(s Session) time.Time {
+	if s.EndTime != nil {
+		return *s.EndTime
+	}
+	return *s.StartTime
+}
@@ -0,0 +1,96 @@
+package claquete
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	at := func(hours, minutes int) *time.Time {
+		t := time.Date(2019, time.May, 23, hours, minutes, 0, 0, loc)
+		return &t
+	}
+
+	sched := &Schedule{
+		Sessions: []Session{
+			{CinemaID: 656, MovieID: 8427, Room: Room{Number: 2}, StartTime: at(21, 0)},
+			{CinemaID: 656, MovieID: 8427, Room: Room{Number: 2}, StartTime: at(14, 0)},
+			{CinemaID: 656, MovieID: 8600, Room: Room{Number: 1}, StartTime: at(20, 15)},
+			{CinemaID: 656, MovieID: 8600, Room: Room{Number: 2}, StartTime: at(16, 40)},
+			{CinemaID: 656, MovieID: 8600, Room: Room{Number: 1}},
+		},
+	}
+
+	timeline := sched.Timeline()
+	if len(timeline) != 2 || timeline[0].Room.Number != 1 || timeline[1].Room.Number != 2 {
+		t.Fatalf("unexpected timeline %+v", timeline)
+	}
+	if len(timeline[0].Sessions) != 1 || len(timeline[1].Sessions) != 3 {
+		t.Fatalf("unexpected timeline %+v", timeline)
+	}
+	if !timeline[1].Sessions[0].StartTime.Equal(*at(14, 0)) || !timeline[1].Sessions[2].StartTime.Equal(*at(21, 0)) {
+		t.Fatalf("expected sessions in order, got %+v", timeline[1].Sessions)
+	}
+
+	if overlaps := sched.Overlaps(); len(overlaps) != 0 {
+		t.Fatalf("expected no overlaps without end times, got %+v", overlaps)
+	}
+
+	// Mary Poppins takes 130 minutes plus buffer, the 14h session ends 16h30
+	sched.SetEndTimes(map[int]int{8427: 130, 8600: 181}, 20*time.Minute)
+	if end := sched.Sessions[1].EndTime; end == nil || !end.Equal(*at(16, 30)) {
+		t.Fatalf("expected session to end at %s, got %v", at(16, 30), end)
+	}
+	if sched.Sessions[4].EndTime != nil {
+		t.Fatalf("expected no end time without start, got %s", sched.Sessions[4].EndTime)
+	}
+
+	if overlaps := sched.Overlaps(); len(overlaps) != 0 {
+		t.Fatalf("expected no overlaps, got %+v", overlaps)
+	}
+
+	sched.Sessions = append(sched.Sessions, Session{CinemaID: 656, MovieID: 8600, Room: Room{Number: 2}, StartTime: at(18, 0)})
+	sched.SetEndTimes(map[int]int{8427: 130, 8600: 181}, 20*time.Minute)
+
+	overlaps := sched.Overlaps()
+	if len(overlaps) != 2 {
+		t.Fatalf("expected 2 overlaps, got %+v", overlaps)
+	}
+	if !overlaps[0].First.StartTime.Equal(*at(16, 40)) || !overlaps[0].Second.StartTime.Equal(*at(18, 0)) {
+		t.Fatalf("unexpected overlap %+v", overlaps[0])
+	}
+	if !overlaps[1].First.StartTime.Equal(*at(18, 0)) || !overlaps[1].Second.StartTime.Equal(*at(21, 0)) {
+		t.Fatalf("unexpected overlap %+v", overlaps[1])
+	}
+
+	b, err := json.Marshal(overlaps[0])
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+	if !strings.HasSuffix(string(b), `"room":2}`) || !strings.Contains(string(b), `"room_info":{`) {
+		t.Fatalf("unexpected JSON %s", b)
+	}
+}
+
+func TestParseScheduleNoOverlaps(t *testing.T) {
+	f, err := os.Open("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sched, err := ParseSchedule(f, "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	// Two sessions starting together in a room means notes were expanded
+	// into the wrong days.
+	if overlaps := sched.Overlaps(); len(overlaps) != 0 {
+		t.Fatalf("expected no overlaps, got %+v", overlaps)
+	}
+}
@@ -0,0 +1,76 @@
+package claquete
+
+import (
+	"strings"
+
+	"github.com/dsbezerra/claqueteapi/util"
+)
+
+// cityTimeZones lists, by state name, the cities whose time zone differs
+// from the one of their state, see getTimeZone.
+var cityTimeZones = map[string]map[string]string{
+	// Southwest of Amazonas follows the time of Acre
+	Amazonas: {
+		"Atalaia do Norte":      "America/Eirunepe",
+		"Benjamin Constant":     "America/Eirunepe",
+		"Boca do Acre":          "America/Eirunepe",
+		"Eirunepé":              "America/Eirunepe",
+		"Envira":                "America/Eirunepe",
+		"Guajará":               "America/Eirunepe",
+		"Ipixuna":               "America/Eirunepe",
+		"Itamarati":             "America/Eirunepe",
+		"Jutaí":                 "America/Eirunepe",
+		"Lábrea":                "America/Eirunepe",
+		"Pauini":                "America/Eirunepe",
+		"São Paulo de Olivença": "America/Eirunepe",
+		"Tabatinga":             "America/Eirunepe",
+	},
+	// West of Pará
+	Para: {
+		"Alenquer":         "America/Santarem",
+		"Aveiro":           "America/Santarem",
+		"Belterra":         "America/Santarem",
+		"Brasil Novo":      "America/Santarem",
+		"Curuá":            "America/Santarem",
+		"Faro":             "America/Santarem",
+		"Itaituba":         "America/Santarem",
+		"Jacareacanga":     "America/Santarem",
+		"Juruti":           "America/Santarem",
+		"Medicilândia":     "America/Santarem",
+		"Mojuí dos Campos": "America/Santarem",
+		"Monte Alegre":     "America/Santarem",
+		"Novo Progresso":   "America/Santarem",
+		"Óbidos":           "America/Santarem",
+		"Oriximiná":        "America/Santarem",
+		"Placas":           "America/Santarem",
+		"Prainha":          "America/Santarem",
+		"Rurópolis":        "America/Santarem",
+		"Santarém":         "America/Santarem",
+		"Terra Santa":      "America/Santarem",
+		"Trairão":          "America/Santarem",
+		"Uruará":           "America/Santarem",
+	},
+	Pernambuco: {
+		"Fernando de Noronha": "America/Noronha",
+	},
+}
+
+// getCityTimeZone returns the time zone of the city in the state with the
+// given name, which is the one of the state for most cities. Names are
+// compared ignoring case and accents.
+func getCityTimeZone(state, city string) string {
+	if city != "" {
+		slug := util.CreateSlug(strings.TrimSpace(city))
+		for s, cities := range cityTimeZones {
+			if util.CreateSlug(s) != util.CreateSlug(strings.TrimSpace(state)) {
+				continue
+			}
+			for name, tz := range cities {
+				if util.CreateSlug(name) == slug {
+					return tz
+				}
+			}
+		}
+	}
+	return getTimeZone(state)
+}
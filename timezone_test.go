@@ -0,0 +1,55 @@
+package claquete
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetCityTimeZone(t *testing.T) {
+	cases := []struct {
+		state, city, expected string
+	}{
+		{Amazonas, "Manaus", "America/Manaus"},
+		{Amazonas, "Eirunepé", "America/Eirunepe"},
+		{"amazonas", "TABATINGA", "America/Eirunepe"},
+		{Para, "Belém", "America/Belem"},
+		{"Para", "Santarem", "America/Santarem"},
+		{Pernambuco, "Fernando de Noronha", "America/Noronha"},
+		{Pernambuco, "Recife", "America/Recife"},
+		{MinasGerais, "Eirunepé", "America/Sao_Paulo"},
+		{Acre, "", "America/Rio_Branco"},
+	}
+	for _, c := range cases {
+		if actual := getCityTimeZone(c.state, c.city); actual != c.expected {
+			t.Fatalf("%s, %s: expected %s, got %s", c.city, c.state, c.expected, actual)
+		}
+	}
+}
+
+func TestParseScheduleCityTimeZone(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/programacao-656.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.NewReplacer(
+		"por cinemas em Minas Gerais", "por cinemas em Amazonas",
+		"Montes Claros - MG", "Eirunepé - AM",
+	).Replace(string(b))
+
+	sched, err := ParseSchedule(strings.NewReader(page), "http://claquete.com.br/programacao/656/cinema-656.html")
+	if err != nil {
+		t.Fatalf("expected no error, but got error: %s", err.Error())
+	}
+
+	if sched.Cinema.TimeZone != "America/Eirunepe" {
+		t.Fatalf("expected America/Eirunepe, got %s", sched.Cinema.TimeZone)
+	}
+
+	loc, _ := time.LoadLocation("America/Eirunepe")
+	expected := time.Date(2019, time.May, 16, 14, 0, 0, 0, loc)
+	if first := sched.Sessions[0]; !first.StartTime.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, first.StartTime)
+	}
+}
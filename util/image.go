@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"image" // Added to understand PNG/JPEG formatted images.
 	_ "image/jpeg"
 	_ "image/png"
@@ -20,15 +21,32 @@ type (
 
 // GetImage gets an image config from a given URL
 func GetImage(u string) (*Image, error) {
+	return GetImageContext(context.Background(), u)
+}
+
+// GetImageContext is like GetImage but aborts when ctx is done
+func GetImageContext(ctx context.Context, u string) (*Image, error) {
+	client := &http.Client{
+		Timeout: time.Second * 10,
+	}
+	return GetImageWithClient(ctx, client, u)
+}
+
+// GetImageWithClient is like GetImageContext but uses the given client
+func GetImageWithClient(ctx context.Context, client *http.Client, u string) (*Image, error) {
 	parsedURL, err := url.Parse(u)
 	if err != nil {
 		return nil, err
 	}
-	client := &http.Client{
-		Timeout: time.Second * 10,
+	req, err := http.NewRequest(http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, err
 	}
-	resp, err := client.Get(parsedURL.String())
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -1,15 +1,27 @@
 package util
 
 import (
-	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
 func TestGetImage(t *testing.T) {
-	image, err := GetImage("http://www.claquete.com/fotos/filmes/poster/8427_medio.jpg")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		jpeg.Encode(w, image.NewRGBA(image.Rect(0, 0, 200, 300)), nil)
+	}))
+	defer ts.Close()
+
+	u := ts.URL + "/fotos/filmes/poster/8427_medio.jpg"
+	img, err := GetImage(u)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	fmt.Println(image)
+	if img.URL != u || img.Width != 200 || img.Height != 300 || img.Resolution != 60000 || img.Format != "jpeg" {
+		t.Fatalf("unexpected image %+v", img)
+	}
 }
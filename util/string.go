@@ -86,7 +86,7 @@ func BreakBySpaces(str string) (string, string) {
 // StringToTime ...
 func StringToTime(str, sep string, loc *time.Location) (*time.Time, error) {
 	if loc == nil {
-		loc, _ = time.LoadLocation("America/Sao_Paulo")
+		loc = LoadLocation("America/Sao_Paulo")
 	}
 
 	var result time.Time
@@ -69,7 +69,6 @@ func CreateSlug(title string) string {
 	t := transform.Chain(norm.NFD, transform.RemoveFunc(isMn), norm.NFC)
 	_, _, e := t.Transform(b, []byte(title), true)
 	if e != nil {
-		fmt.Println(e)
 		return ""
 	}
 
@@ -124,7 +123,7 @@ func CreateDate(str, sep string) (time.Time, bool, error) {
 		}
 
 		if ok {
-			loc, _ := time.LoadLocation("America/Sao_Paulo")
+			loc := LoadLocation("America/Sao_Paulo")
 			result = time.Date(y, m, d, 0, 0, 0, 0, loc)
 		} else {
 			err = fmt.Errorf("couldn't create date from text: %s", str)
@@ -181,3 +180,42 @@ func GetMonth(str string) time.Month {
 
 	return result
 }
+
+// zoneOffsets are the UTC offsets, in hours, of the Brazilian time zones,
+// which have no daylight saving time since 2019.
+var zoneOffsets = map[string]int{
+	"America/Noronha":      -2,
+	"America/Sao_Paulo":    -3,
+	"America/Araguaina":    -3,
+	"America/Bahia":        -3,
+	"America/Belem":        -3,
+	"America/Fortaleza":    -3,
+	"America/Maceio":       -3,
+	"America/Recife":       -3,
+	"America/Santarem":     -3,
+	"America/Boa_Vista":    -4,
+	"America/Campo_Grande": -4,
+	"America/Cuiaba":       -4,
+	"America/Manaus":       -4,
+	"America/Porto_Velho":  -4,
+	"America/Eirunepe":     -5,
+	"America/Rio_Branco":   -5,
+}
+
+// LoadLocation returns the location with the given name. When the time
+// zone database isn't available it returns a fixed zone with the offset
+// of the Brazilian time zone, or of Brasília time for other names.
+func LoadLocation(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return fixedLocation(name)
+}
+
+func fixedLocation(name string) *time.Location {
+	offset, ok := zoneOffsets[name]
+	if !ok {
+		offset = -3
+	}
+	return time.FixedZone(fmt.Sprintf("%03d", offset), offset*60*60)
+}
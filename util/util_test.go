@@ -1,6 +1,9 @@
 package util
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestCreateSlug(t *testing.T) {
 	title := "Vingadores: Ultimato"
@@ -19,3 +22,27 @@ func TestCreateSlug(t *testing.T) {
 		t.Fatalf("expected %s, got %s", expected, slug)
 	}
 }
+
+func TestLoadLocation(t *testing.T) {
+	if loc := LoadLocation("America/Manaus"); loc == nil {
+		t.Fatal("expected a location for America/Manaus")
+	}
+
+	// Without the time zone database
+	cases := map[string]int{
+		"America/Noronha":   -2 * 60 * 60,
+		"America/Sao_Paulo": -3 * 60 * 60,
+		"America/Manaus":    -4 * 60 * 60,
+		"America/Eirunepe":  -5 * 60 * 60,
+		"Nowhere/Unknown":   -3 * 60 * 60,
+	}
+	for name, expected := range cases {
+		loc := fixedLocation(name)
+		if _, offset := time.Date(2024, time.January, 1, 0, 0, 0, 0, loc).Zone(); offset != expected {
+			t.Fatalf("%s: expected offset %d, got %d", name, expected, offset)
+		}
+	}
+	if name, _ := time.Now().In(LoadLocation("Nowhere/Unknown")).Zone(); name != "-03" {
+		t.Fatalf("expected zone -03, got %s", name)
+	}
+}